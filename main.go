@@ -139,7 +139,7 @@ func getItem(w http.ResponseWriter, r *http.Request) {
 
 	var response *http.Response
 	if response, err = client.Get(resource); err != nil {
-		log.Printf("Error: ", err.Error())
+		log.Printf("Error: %s", err.Error())
 		return
 	}
 
@@ -163,7 +163,7 @@ func postItem(w http.ResponseWriter, r *http.Request) {
 	response, err := client.Post("/items/", item)
 
 	if err != nil {
-		log.Printf("Error: ", err)
+		log.Printf("Error: %s", err)
 		return
 	}
 	printOutput(w, response)
@@ -181,7 +181,7 @@ func getSites(w http.ResponseWriter, r *http.Request) {
 
 	var response *http.Response
 	if response, err = client.Get(resource); err != nil {
-		log.Printf("Error: ", err.Error())
+		log.Printf("Error: %s", err.Error())
 		return
 	}
 
@@ -200,7 +200,7 @@ func me(w http.ResponseWriter, r *http.Request) {
 	client, err := sdk.Meli(clientID, code, clientSecret, redirectURL)
 
 	if err != nil {
-		log.Printf("Error: ", err.Error())
+		log.Printf("Error: %s", err.Error())
 		return
 	}
 
@@ -212,18 +212,20 @@ func me(w http.ResponseWriter, r *http.Request) {
 
 	var response *http.Response
 	if response, err = client.Get("/users/me"); err != nil {
-		log.Printf("Error: ", err.Error())
-		return
-	}
 
-	if response.StatusCode == http.StatusForbidden {
+		if sdk.IsForbidden(err) || sdk.IsUnauthorized(err) {
+
+			url := sdk.GetAuthURL(clientID, sdk.AuthURLMLA, host+"/"+user+"/users/me")
+			log.Printf("Returning Authentication URL:%s\n", url)
 
-		url := sdk.GetAuthURL(clientID, sdk.AuthURLMLA, host+"/"+user+"/users/me")
-		log.Printf("Returning Authentication URL:%s\n", url)
+			//		userForbidden[user] = ""
 
-		//		userForbidden[user] = ""
+			http.Redirect(w, r, url, 302)
+			return
+		}
 
-		http.Redirect(w, r, url, 302)
+		log.Printf("Error: %s", err.Error())
+		return
 	}
 
 	printOutput(w, response)
@@ -231,7 +233,7 @@ func me(w http.ResponseWriter, r *http.Request) {
 
 /*
 This method responses when clicking in addresses link. After that, this will call
-https://api.mercadolibre.com/users/214509008/addresses?access_token=$ACCESS_TOKEN
+https://api.mercadolibre.com/users/214509008/addresses (sending the access token in the Authorization header)
 to get the addresses of the user.
 */
 func addresses(w http.ResponseWriter, r *http.Request) {
@@ -247,28 +249,29 @@ func addresses(w http.ResponseWriter, r *http.Request) {
 	client, err := sdk.Meli(clientID, code, clientSecret, redirectURL)
 
 	if err != nil {
-		log.Printf("Error: ", err.Error())
+		log.Printf("Error: %s", err.Error())
 		return
 	}
 
 	var response *http.Response
 	if response, err = client.Get(resource); err != nil {
-		log.Printf("Error: ", err.Error())
-		return
-	}
 
-	/*Example
-	  If the API to be called needs authorization/authentication (private api), then the authentication URL needs to be generated.
-	  Once you generate the URL and call it, you will be redirected to a ML login page where your credentials will be asked. Then, after
-	  entering your credentials you will obtain a CODE which will be used to get all the authorization tokens.
-	*/
-	if response.StatusCode == http.StatusForbidden {
-		url := sdk.GetAuthURL(clientID, sdk.AuthURLMLA, redirectURL)
-		body, _ := ioutil.ReadAll(response.Body)
-		log.Printf("Returning Authentication URL:%s\n", url)
-		log.Printf("Error:%s", body)
-
-		http.Redirect(w, r, url, 302)
+		/*Example
+		  If the API to be called needs authorization/authentication (private api), then the authentication URL needs to be generated.
+		  Once you generate the URL and call it, you will be redirected to a ML login page where your credentials will be asked. Then, after
+		  entering your credentials you will obtain a CODE which will be used to get all the authorization tokens.
+		*/
+		if sdk.IsForbidden(err) || sdk.IsUnauthorized(err) {
+			url := sdk.GetAuthURL(clientID, sdk.AuthURLMLA, redirectURL)
+			log.Printf("Returning Authentication URL:%s\n", url)
+			log.Printf("Error:%s", err)
+
+			http.Redirect(w, r, url, 302)
+			return
+		}
+
+		log.Printf("Error: %s", err.Error())
+		return
 	}
 
 	printOutput(w, response)
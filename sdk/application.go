@@ -0,0 +1,92 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strconv"
+)
+
+/*
+NewApplicationClient returns a client which acts on behalf of the application itself, rather than on behalf of a user.
+Its token is obtained by the client_credentials grant, and obtained again whenever it expires.
+
+The client is cached like the ones of the users, under user id 0. Only ClientID and Secret are needed in config,
+along with the usual HTTPClient, RetryPolicy, RateLimiter and Clock. Its token is not saved in the TokenStore.
+*/
+func NewApplicationClient(config MeliConfig) (*Client, error) {
+
+	return NewApplicationClientContext(context.Background(), config)
+}
+
+/*NewApplicationClientContext works as NewApplicationClient, but the token request is bound to ctx.*/
+func NewApplicationClientContext(ctx context.Context, config MeliConfig) (*Client, error) {
+
+	registry := config.registry()
+
+	if registry != nil {
+		if client, ok := registry.Get(config.ClientID, 0); ok && client.application {
+			client.reconfigure(config)
+			return client, nil
+		}
+	}
+
+	config.UserCode = ""
+	config.TokenStore = nil
+	config.TokenRefresher = ClientCredentialsTokenRefresher{}
+
+	client := newClient(config)
+	client.application = true
+
+	if err := client.refreshToken(ctx); err != nil {
+		if debugEnable {
+			log.Printf("Error while obtaining the application token %s\n", err.Error())
+		}
+		return nil, err
+	}
+
+	if registry != nil {
+		client = registry.add(ClientKey{config.ClientID, 0}, "", client)
+	}
+
+	return client, nil
+}
+
+/*ClientCredentialsTokenRefresher obtains a new application token by the client_credentials grant.*/
+type ClientCredentialsTokenRefresher struct {
+}
+
+func (refresher ClientCredentialsTokenRefresher) RefreshToken(ctx context.Context, client *Client) error {
+
+	form := url.Values{}
+	form.Set("grant_type", ClientCredentials)
+	form.Set("client_id", strconv.FormatInt(client.id, 10))
+	form.Set("client_secret", client.getSecret())
+
+	var auth Authorization
+	if err := client.requestToken(ctx, form, &auth); err != nil {
+		return err
+	}
+
+	//The token does not belong to any user, even if the API tells the owner of the application
+	auth.UserID = 0
+	client.setAuthorization(auth)
+
+	return nil
+}
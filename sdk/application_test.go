@@ -0,0 +1,98 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*newApplicationTestConfig returns a config whose token requests are answered by the client_credentials grant only.*/
+func newApplicationTestConfig(registry *ClientRegistry, clock Clock, grants *int32) MeliConfig {
+	return MeliConfig{
+		ClientID: CLIENT_ID,
+		Secret:   CLIENT_SECRET,
+		Registry: registry,
+		Clock:    clock,
+		HTTPClient: HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/oauth/token") {
+				body, _ := ioutil.ReadAll(req.Body)
+				form, _ := url.ParseQuery(string(body))
+				if form.Get("grant_type") != "client_credentials" || form.Get("client_secret") != CLIENT_SECRET {
+					return &http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(strings.NewReader(`{"error":"invalid_client"}`))}, nil
+				}
+				atomic.AddInt32(grants, 1)
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(
+					`{"access_token":"valid token","token_type":"bearer","expires_in":21600,"scope":"offline_access","user_id":214509008}`))}, nil
+			}
+			return MockHttpClient{}.Do(req)
+		}),
+	}
+}
+
+func Test_application_client_is_authorized_by_the_client_credentials_grant(t *testing.T) {
+
+	var grants int32
+	registry := NewClientRegistry(10, 0)
+	clock := &MockClock{now: time.Now()}
+
+	client, err := NewApplicationClient(newApplicationTestConfig(registry, clock, &grants))
+
+	if err != nil || !client.IsAuthorized() || client.Authorization().UserID != 0 || grants != 1 {
+		log.Printf("Error: the client should have been authorized by the client_credentials grant. error: %v\n", err)
+		t.FailNow()
+	}
+
+	resp, err := client.Post("/items", "{\"foo\":\"bar\"}")
+
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		log.Printf("Error: the application token should have been sent. error: %v\n", err)
+		t.FailNow()
+	}
+
+	if cached, _ := NewApplicationClient(newApplicationTestConfig(registry, clock, &grants)); cached != client || grants != 1 {
+		log.Printf("Error: the cached application client should have been returned\n")
+		t.FailNow()
+	}
+
+	//There is no refresh token, so the grant is requested again once the token expires
+	clock.Advance(7 * time.Hour)
+
+	if _, err := client.Post("/items", "{\"foo\":\"bar\"}"); err != nil || atomic.LoadInt32(&grants) != 2 {
+		log.Printf("Error: a new application token should have been obtained. grants: %d error: %v\n", grants, err)
+		t.FailNow()
+	}
+}
+
+func Test_application_client_returns_the_error_of_the_grant(t *testing.T) {
+
+	var grants int32
+	config := newApplicationTestConfig(nil, nil, &grants)
+	config.Secret = "wrong secret"
+	config.DisableCache = true
+
+	if _, err := NewApplicationClient(config); err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		log.Printf("Error: the APIError of the grant was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+)
+
+/*ErrStateMismatch is returned by ExchangeCode when the state received by the callback is not the one which was sent.*/
+var ErrStateMismatch = errors.New("the state received does not match the one sent")
+
+const codeChallengeMethod = "S256"
+
+/*
+AuthURLBuilder builds the URL the user has to be redirected to in order to authorize the application,
+protected by a random state against CSRF and by PKCE against the interception of the code.
+*/
+type AuthURLBuilder struct {
+	ClientID    int64
+	BaseSite    string // Site id or base URL of its authorization page, i.e. MLA or AuthURLMLA
+	CallBackURL string
+}
+
+/*
+AuthRequest is an authorization in progress. State and CodeVerifier have to be kept by the application
+(i.e. in the user session) until the user comes back to the callback. CodeVerifier must never be sent to the browser.
+*/
+type AuthRequest struct {
+	URL          string
+	State        string
+	CodeVerifier string
+}
+
+/*Build returns a new AuthRequest, with a random state and code_verifier. The code_challenge is sent by using S256.*/
+func (builder AuthURLBuilder) Build() (*AuthRequest, error) {
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := authBaseURL(builder.BaseSite)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL := newAuthorizationURL(base + "/authorization")
+	authURL.addResponseType("code")
+	authURL.addClientId(builder.ClientID)
+	authURL.addRedirectURI(builder.CallBackURL)
+	authURL.addState(state)
+	authURL.addCodeChallenge(codeChallenge(verifier), codeChallengeMethod)
+
+	return &AuthRequest{URL: authURL.string(), State: state, CodeVerifier: verifier}, nil
+}
+
+/*
+ExchangeCode validates the state and the code received by the callback of the given AuthRequest,
+and returns a client authorized with them. The code_verifier of the request is sent along with the code.
+*/
+func ExchangeCode(ctx context.Context, config MeliConfig, request AuthRequest, state string, code string) (*Client, error) {
+
+	if request.State == "" || subtle.ConstantTimeCompare([]byte(request.State), []byte(state)) != 1 {
+		return nil, ErrStateMismatch
+	}
+
+	if code == "" {
+		return nil, errors.New("the authorization code is empty")
+	}
+
+	config.UserCode = code
+	config.CodeVerifier = request.CodeVerifier
+
+	return MeliClientContext(ctx, config)
+}
+
+/*randomToken returns 32 random bytes encoded as URL-safe base64, which is a valid code_verifier as well.*/
+func randomToken() (string, error) {
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func codeChallenge(verifier string) string {
+
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func Test_auth_URL_carries_state_and_S256_code_challenge(t *testing.T) {
+
+	builder := AuthURLBuilder{ClientID: CLIENT_ID, BaseSite: AuthURLMLA, CallBackURL: "http://someurl.com"}
+
+	request, err := builder.Build()
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	parsed, _ := url.Parse(request.URL)
+	query := parsed.Query()
+
+	if !strings.HasPrefix(request.URL, AuthURLMLA+"/authorization?") || query.Get("client_id") != "123456" ||
+		query.Get("redirect_uri") != "http://someurl.com" || query.Get("response_type") != "code" {
+		log.Printf("Error: unexpected URL %s\n", request.URL)
+		t.FailNow()
+	}
+
+	//Challenge of the example verifier given by RFC 7636
+	if codeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk") != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		log.Printf("Error: the code challenge is not properly computed\n")
+		t.FailNow()
+	}
+
+	if query.Get("state") != request.State || query.Get("code_challenge") != codeChallenge(request.CodeVerifier) ||
+		query.Get("code_challenge_method") != "S256" || len(request.CodeVerifier) != 43 {
+		log.Printf("Error: the URL should carry the state and the code challenge %s\n", request.URL)
+		t.FailNow()
+	}
+
+	if another, _ := builder.Build(); another.State == request.State || another.CodeVerifier == request.CodeVerifier {
+		log.Printf("Error: every request should have its own state and code verifier\n")
+		t.FailNow()
+	}
+}
+
+func Test_code_is_exchanged_with_its_code_verifier_when_state_matches(t *testing.T) {
+
+	var verifier string
+
+	config := MeliConfig{
+		ClientID:     CLIENT_ID,
+		Secret:       CLIENT_SECRET,
+		CallBackURL:  "http://www.example.com",
+		DisableCache: true,
+		HTTPClient: HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			form, _ := url.ParseQuery(string(body))
+			verifier = form.Get("code_verifier")
+			req.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+			return MockHttpClient{}.Do(req)
+		}),
+	}
+
+	request := AuthRequest{State: "expected state", CodeVerifier: "the verifier"}
+
+	if _, err := ExchangeCode(context.Background(), config, request, "forged state", USER_CODE); !errors.Is(err, ErrStateMismatch) {
+		log.Printf("Error: ErrStateMismatch was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if verifier != "" {
+		log.Printf("Error: the code should not have been exchanged\n")
+		t.FailNow()
+	}
+
+	client, err := ExchangeCode(context.Background(), config, request, "expected state", USER_CODE)
+
+	if err != nil || !client.IsAuthorized() || verifier != "the verifier" {
+		log.Printf("Error: the code should have been exchanged along with the verifier. verifier: %s error: %v\n", verifier, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+/*Category of the items of a site. Only the leaves of the tree of categories (without Children) accept items.*/
+type Category struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Picture      string            `json:"picture"`
+	Permalink    string            `json:"permalink"`
+	TotalItems   int               `json:"total_items_in_this_category"`
+	PathFromRoot []CategoryNode    `json:"path_from_root"` // From the root category of the site to this one, included
+	Children     []CategoryNode    `json:"children_categories"`
+	Settings     *CategorySettings `json:"settings"`
+}
+
+/*CategoryNode is a category referenced by another one, i.e. its parents or children.*/
+type CategoryNode struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	TotalItems int    `json:"total_items_in_this_category"`
+}
+
+/*CategorySettings are the conditions the items of a category have to meet.*/
+type CategorySettings struct {
+	ListingAllowed     bool     `json:"listing_allowed"`
+	BuyingModes        []string `json:"buying_modes"`
+	ItemConditions     []string `json:"item_conditions"`
+	Currencies         []string `json:"currencies"`
+	MaxPicturesPerItem int      `json:"max_pictures_per_item"`
+	MaxTitleLength     int      `json:"max_title_length"`
+	ShippingModes      []string `json:"shipping_modes"`
+}
+
+/*CategoryAttribute is an attribute the items of a category can, or must, have.*/
+type CategoryAttribute struct {
+	ID             string           `json:"id"`
+	Name           string           `json:"name"`
+	ValueType      string           `json:"value_type"` // i.e. string, number, number_unit, list, boolean
+	ValueMaxLength int              `json:"value_max_length"`
+	Values         []AttributeValue `json:"values"` // The values accepted, when they are restricted
+	AllowedUnits   []AttributeValue `json:"allowed_units"`
+	DefaultUnit    string           `json:"default_unit"`
+	Hierarchy      string           `json:"hierarchy"`
+	Relevance      int              `json:"relevance"`
+	Tags           struct {
+		Required           bool `json:"required"`
+		CatalogRequired    bool `json:"catalog_required"`
+		AllowVariations    bool `json:"allow_variations"`
+		VariationAttribute bool `json:"variation_attribute"`
+		Hidden             bool `json:"hidden"`
+		ReadOnly           bool `json:"read_only"`
+		Fixed              bool `json:"fixed"`
+	} `json:"tags"`
+}
+
+/*AttributeValue is one of the values, or units, accepted by a CategoryAttribute.*/
+type AttributeValue struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+/*CategoryPrediction is a category suggested for an item by the domain discovery, along with the attributes it infers.*/
+type CategoryPrediction struct {
+	DomainID     string      `json:"domain_id"`
+	DomainName   string      `json:"domain_name"`
+	CategoryID   string      `json:"category_id"`
+	CategoryName string      `json:"category_name"`
+	Attributes   []Attribute `json:"attributes"`
+}
+
+/*CategoriesService gives typed access to the categories of the sites and their attributes.*/
+type CategoriesService struct {
+	client *Client
+}
+
+/*Categories returns the service to walk the categories of the sites.*/
+func (client *Client) Categories() *CategoriesService {
+	return &CategoriesService{client: client}
+}
+
+/*SiteCategories retrieves the root categories of a site, i.e. MLA. Unknown sites are rejected with ErrUnknownSite.*/
+func (service *CategoriesService) SiteCategories(ctx context.Context, siteID string) ([]CategoryNode, error) {
+
+	site, err := knownSite(siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []CategoryNode
+	if err := service.client.getJSON(ctx, "/sites/"+site.ID+"/categories", &categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+/*Get retrieves the category with the given id, along with its path from the root and its children.*/
+func (service *CategoriesService) Get(ctx context.Context, id string) (*Category, error) {
+
+	category := new(Category)
+	if err := service.client.getJSON(ctx, "/categories/"+pathID(id), category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+/*Attributes retrieves the attributes of the items of a category.*/
+func (service *CategoriesService) Attributes(ctx context.Context, id string) ([]CategoryAttribute, error) {
+
+	var attributes []CategoryAttribute
+	if err := service.client.getJSON(ctx, "/categories/"+pathID(id)+"/attributes", &attributes); err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+/*
+Predict suggests up to limit categories of a site for an item with the given title, the most likely first.
+Unknown sites are rejected with ErrUnknownSite.
+*/
+func (service *CategoriesService) Predict(ctx context.Context, siteID string, title string, limit int) ([]CategoryPrediction, error) {
+
+	site, err := knownSite(siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if title == "" {
+		return nil, errors.New("the title is empty")
+	}
+
+	query := url.Values{}
+	query.Set("q", title)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var predictions []CategoryPrediction
+	if err := service.client.getJSON(ctx, "/sites/"+site.ID+"/domain_discovery/search?"+query.Encode(), &predictions); err != nil {
+		return nil, err
+	}
+
+	return predictions, nil
+}
+
+/*MissingAttributes retrieves the attributes of the category of item and returns the required ones it is missing.*/
+func (service *CategoriesService) MissingAttributes(ctx context.Context, item Item) ([]CategoryAttribute, error) {
+
+	if item.CategoryID == "" {
+		return nil, errors.New("the item has no category")
+	}
+
+	attributes, err := service.Attributes(ctx, item.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return MissingRequiredAttributes(attributes, item), nil
+}
+
+/*
+MissingRequiredAttributes returns the required attributes, out of the given attributes of a category, which item
+does not have a value for. An attribute can be set either on the item or on every one of its variations.
+*/
+func MissingRequiredAttributes(attributes []CategoryAttribute, item Item) []CategoryAttribute {
+
+	var missing []CategoryAttribute
+
+	for _, attribute := range attributes {
+
+		if !attribute.Tags.Required || attribute.Tags.ReadOnly {
+			continue
+		}
+
+		if !hasAttribute(item.Attributes, attribute.ID) && !allVariationsHaveAttribute(item.Variations, attribute.ID) {
+			missing = append(missing, attribute)
+		}
+	}
+
+	return missing
+}
+
+func allVariationsHaveAttribute(variations []Variation, id string) bool {
+
+	if len(variations) == 0 {
+		return false
+	}
+
+	for _, variation := range variations {
+		if !hasAttribute(variation.AttributeCombinations, id) && !hasAttribute(variation.Attributes, id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasAttribute(attributes []Attribute, id string) bool {
+
+	for _, attribute := range attributes {
+		if attribute.ID == id && (attribute.ValueID != "" || attribute.ValueName != "") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"testing"
+)
+
+const testCategoryAttributes = `[
+	{"id":"BRAND","name":"Marca","value_type":"string","tags":{"required":true}},
+	{"id":"MODEL","name":"Modelo","value_type":"string","tags":{"required":true}},
+	{"id":"COLOR","name":"Color","value_type":"list","values":[{"id":"52049","name":"Negro"}],"tags":{"required":true,"allow_variations":true}},
+	{"id":"ITEM_CONDITION","name":"Condición","tags":{"required":true,"read_only":true}},
+	{"id":"LENS_SIZE","name":"Tamaño","value_type":"number_unit","allowed_units":[{"id":"mm","name":"mm"}],"tags":{}}]`
+
+func Test_Categories_walk_the_tree_and_predict_categories(t *testing.T) {
+
+	var query string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sites/MLA/categories":
+			w.Write([]byte(`[{"id":"MLA5725","name":"Accesorios para Vehículos"},{"id":"MLA1051","name":"Celulares y Teléfonos"}]`))
+		case "/categories/MLA1912":
+			w.Write([]byte(`{"id":"MLA1912","name":"Anteojos de Sol","total_items_in_this_category":100,
+				"path_from_root":[{"id":"MLA1430","name":"Ropa y Accesorios"},{"id":"MLA1912","name":"Anteojos de Sol"}],
+				"children_categories":[],"settings":{"listing_allowed":true,"max_pictures_per_item":12}}`))
+		case "/categories/MLA1912/attributes":
+			w.Write([]byte(testCategoryAttributes))
+		case "/sites/MLA/domain_discovery/search":
+			query = r.URL.RawQuery
+			w.Write([]byte(`[{"domain_id":"MLA-SUNGLASSES","domain_name":"Anteojos de sol","category_id":"MLA1912","category_name":"Anteojos de Sol",
+				"attributes":[{"id":"BRAND","name":"Marca","value_id":"1","value_name":"Ray-Ban"}]}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer closeServer()
+
+	categories := client.Categories()
+	ctx := context.Background()
+
+	roots, err := categories.SiteCategories(ctx, "MLA")
+	if err != nil || len(roots) != 2 || roots[1].ID != "MLA1051" {
+		log.Printf("Error: unexpected categories %+v %v\n", roots, err)
+		t.FailNow()
+	}
+
+	category, err := categories.Get(ctx, "MLA1912")
+	if err != nil || len(category.PathFromRoot) != 2 || category.PathFromRoot[0].ID != "MLA1430" ||
+		category.TotalItems != 100 || !category.Settings.ListingAllowed {
+		log.Printf("Error: unexpected category %+v %v\n", category, err)
+		t.FailNow()
+	}
+
+	attributes, err := categories.Attributes(ctx, "MLA1912")
+	if err != nil || len(attributes) != 5 || !attributes[2].Tags.AllowVariations || attributes[2].Values[0].Name != "Negro" ||
+		attributes[4].AllowedUnits[0].ID != "mm" {
+		log.Printf("Error: unexpected attributes %+v %v\n", attributes, err)
+		t.FailNow()
+	}
+
+	predictions, err := categories.Predict(ctx, "MLA", "Ray-Ban Wayfarer", 1)
+	if err != nil || len(predictions) != 1 || predictions[0].CategoryID != "MLA1912" || predictions[0].Attributes[0].ValueName != "Ray-Ban" {
+		log.Printf("Error: unexpected predictions %+v %v\n", predictions, err)
+		t.FailNow()
+	}
+
+	if query != "limit=1&q=Ray-Ban+Wayfarer" {
+		log.Printf("Error: unexpected query %s\n", query)
+		t.FailNow()
+	}
+}
+
+func Test_Categories_MissingAttributes_reports_the_required_attributes_without_value(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testCategoryAttributes))
+	})
+	defer closeServer()
+
+	item := Item{
+		CategoryID: "MLA1912",
+		Attributes: []Attribute{{ID: "BRAND", ValueName: "Ray-Ban"}, {ID: "MODEL"}},
+		Variations: []Variation{
+			{AttributeCombinations: []Attribute{{ID: "COLOR", ValueID: "52049"}}},
+			{AttributeCombinations: []Attribute{{ID: "COLOR", ValueName: "Azul"}}},
+		},
+	}
+
+	missing, err := client.Categories().MissingAttributes(context.Background(), item)
+	if err != nil || len(missing) != 1 || missing[0].ID != "MODEL" {
+		log.Printf("Error: only MODEL should be missing, obtained %+v %v\n", missing, err)
+		t.FailNow()
+	}
+
+	item.Variations = append(item.Variations, Variation{})
+
+	if missing := MissingRequiredAttributes(mustAttributes(t, client), item); len(missing) != 2 || missing[1].ID != "COLOR" {
+		log.Printf("Error: COLOR should be missing when a variation does not set it, obtained %+v\n", missing)
+		t.FailNow()
+	}
+}
+
+func mustAttributes(t *testing.T, client *Client) []CategoryAttribute {
+
+	attributes, err := client.Categories().Attributes(context.Background(), "MLA1912")
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	return attributes
+}
+
+func Test_Categories_reject_unknown_sites(t *testing.T) {
+
+	called := false
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	defer closeServer()
+
+	categories := client.Categories()
+	ctx := context.Background()
+
+	if _, err := categories.SiteCategories(ctx, "MLX"); !errors.Is(err, ErrUnknownSite) {
+		log.Printf("Error: ErrUnknownSite was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if _, err := categories.Predict(ctx, "../users/me", "Ray-Ban", 1); !errors.Is(err, ErrUnknownSite) || called {
+		log.Printf("Error: an unknown site should have been rejected without calling the API, obtained %v\n", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+/*
+Sentinel errors to be used with errors.Is against the errors returned by the sdk.
+i.e. errors.Is(err, sdk.ErrNotFound) reports whether the API answered 404.
+*/
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrInvalidGrant = errors.New("invalid grant")
+	ErrGrantRevoked = errors.New("grant revoked")
+
+	ErrReauthorizationRequired = errors.New("the user has to authorize the application again")
+)
+
+const requestIDHeader = "X-Request-Id"
+
+/*
+APIError is returned whenever mercadolibre API answers with a status code different from 2xx.
+It keeps the error payload sent by the API, so it can be inspected by using errors.As.
+*/
+type APIError struct {
+	StatusCode int          // HTTP status code of the response
+	Method     string       // HTTP method of the request
+	Endpoint   string       // Path of the request, i.e. /items/MLA123
+	RequestID  string       // Value of the X-Request-Id header, useful when contacting mercadolibre
+	Message    string       // "message" field of the payload
+	Code       string       // "error" field of the payload, i.e. not_found or invalid_grant
+	Status     int          // "status" field of the payload
+	Cause      []ErrorCause // "cause" field of the payload
+	Body       []byte       // Raw response body
+}
+
+/*
+RevokedError is returned by Client.Revoke when the user had already revoked the grant of the application,
+i.e. from the mercadolibre site. errors.Is(err, ErrGrantRevoked) reports whether err is a RevokedError.
+*/
+type RevokedError struct {
+	UserID int64
+	Err    error // Error returned by the API
+}
+
+func (e *RevokedError) Error() string {
+	return fmt.Sprintf("the grant of user %d was already revoked: %s", e.UserID, e.Err)
+}
+
+func (e *RevokedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RevokedError) Is(target error) bool {
+	return target == ErrGrantRevoked
+}
+
+/*ErrorCause is one of the entries of the "cause" array sent by the API.*/
+type ErrorCause struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+
+	message := e.Message
+	if message == "" {
+		message = e.Code
+	}
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Endpoint, e.StatusCode, message)
+}
+
+/*Is allows comparing an APIError against the sentinel errors by using errors.Is.*/
+func (e *APIError) Is(target error) bool {
+
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInvalidGrant:
+		return e.Code == "invalid_grant"
+	}
+
+	return false
+}
+
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+func IsInvalidGrant(err error) bool {
+	return errors.Is(err, ErrInvalidGrant)
+}
+
+/*
+newAPIError builds an APIError from the response of req. The response body is consumed and closed.
+*/
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Endpoint:   req.URL.Path,
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+
+	if resp.Body == nil {
+		return apiErr
+	}
+
+	apiErr.Body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	apiErr.parsePayload()
+
+	return apiErr
+}
+
+/*parsePayload fills the APIError from the error payload found in its Body, if any.*/
+func (apiErr *APIError) parsePayload() {
+
+	var payload struct {
+		Message string            `json:"message"`
+		Error   string            `json:"error"`
+		Status  json.RawMessage   `json:"status"`
+		Cause   []json.RawMessage `json:"cause"`
+	}
+
+	if err := json.Unmarshal(apiErr.Body, &payload); err != nil {
+		return
+	}
+
+	apiErr.Message = payload.Message
+	apiErr.Code = payload.Error
+	apiErr.Status = parseStatus(payload.Status)
+
+	for _, raw := range payload.Cause {
+		apiErr.Cause = append(apiErr.Cause, parseCause(raw))
+	}
+}
+
+/*The API sends the status either as a number or as a string.*/
+func parseStatus(raw json.RawMessage) int {
+
+	var status int
+	if err := json.Unmarshal(raw, &status); err == nil {
+		return status
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		status, _ = strconv.Atoi(text)
+	}
+
+	return status
+}
+
+/*The API sends each cause either as a plain string or as an object whose code may be a number.*/
+func parseCause(raw json.RawMessage) ErrorCause {
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return ErrorCause{Message: text}
+	}
+
+	var cause struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+	}
+	json.Unmarshal(raw, &cause)
+
+	code := string(cause.Code)
+	json.Unmarshal(cause.Code, &code)
+
+	return ErrorCause{Code: code, Message: cause.Message}
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
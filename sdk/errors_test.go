@@ -0,0 +1,75 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_APIError_is_returned_with_the_mercadolibre_payload_when_status_code_is_not_2xx(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "request-id")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Item with id MLA123 not found","error":"not_found","status":404,"cause":[{"code":"item.not_found","message":"not found"},"plain cause"]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{apiURL: server.URL, auth: anonymous, httpClient: MeliHTTPClient{Client: server.Client()}}
+
+	resp, err := client.Get("/items/MLA123")
+
+	var apiErr *APIError
+	if resp != nil || !errors.As(err, &apiErr) {
+		log.Printf("Error: An APIError should have been received, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "not_found" || apiErr.Status != 404 ||
+		apiErr.Message != "Item with id MLA123 not found" || apiErr.RequestID != "request-id" ||
+		apiErr.Endpoint != "/items/MLA123" || apiErr.Method != http.MethodGet {
+		log.Printf("Error: unexpected APIError %+v\n", apiErr)
+		t.FailNow()
+	}
+
+	if len(apiErr.Cause) != 2 || apiErr.Cause[0].Code != "item.not_found" || apiErr.Cause[1].Message != "plain cause" {
+		log.Printf("Error: unexpected causes %+v\n", apiErr.Cause)
+		t.FailNow()
+	}
+
+	if !IsNotFound(err) || IsForbidden(err) || IsRateLimited(err) {
+		log.Printf("Error: APIError does not match the expected sentinel errors\n")
+		t.FailNow()
+	}
+}
+
+func Test_APIError_matches_invalid_grant_when_token_exchange_fails(t *testing.T) {
+
+	client := &Client{id: CLIENT_ID, code: "bad code", secret: CLIENT_SECRET, apiURL: API_TEST, httpClient: MockHttpClient{}}
+
+	_, err := client.authorize(context.Background())
+
+	if !IsInvalidGrant(err) {
+		log.Printf("Error: An invalid grant error should have been received, obtained %v\n", err)
+		t.FailNow()
+	}
+}
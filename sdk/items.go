@@ -0,0 +1,322 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/*Status of the items.*/
+const (
+	ItemActive      = "active"
+	ItemPaused      = "paused"
+	ItemClosed      = "closed"
+	ItemUnderReview = "under_review"
+)
+
+/*Max number of ids the API accepts in a single multiget.*/
+const multiGetSize = 20
+
+/*Item is a listing published in mercadolibre. When an Item is sent to the API by Create, its empty fields are left out.*/
+type Item struct {
+	ID                string      `json:"id,omitempty"`
+	SiteID            string      `json:"site_id,omitempty"`
+	Title             string      `json:"title,omitempty"`
+	SellerID          int64       `json:"seller_id,omitempty"`
+	CategoryID        string      `json:"category_id,omitempty"`
+	Price             float64     `json:"price,omitempty"`
+	BasePrice         float64     `json:"base_price,omitempty"`
+	CurrencyID        string      `json:"currency_id,omitempty"`
+	AvailableQuantity int         `json:"available_quantity,omitempty"`
+	SoldQuantity      int         `json:"sold_quantity,omitempty"`
+	BuyingMode        string      `json:"buying_mode,omitempty"`
+	ListingTypeID     string      `json:"listing_type_id,omitempty"`
+	Condition         string      `json:"condition,omitempty"`
+	Permalink         string      `json:"permalink,omitempty"`
+	Thumbnail         string      `json:"thumbnail,omitempty"`
+	VideoID           string      `json:"video_id,omitempty"`
+	Status            string      `json:"status,omitempty"`
+	SubStatus         []string    `json:"sub_status,omitempty"`
+	Tags              []string    `json:"tags,omitempty"`
+	Pictures          []Picture   `json:"pictures,omitempty"`
+	Attributes        []Attribute `json:"attributes,omitempty"`
+	Variations        []Variation `json:"variations,omitempty"`
+	SaleTerms         []SaleTerm  `json:"sale_terms,omitempty"`
+	Shipping          *Shipping   `json:"shipping,omitempty"`
+	DateCreated       *time.Time  `json:"date_created,omitempty"`
+	LastUpdated       *time.Time  `json:"last_updated,omitempty"`
+}
+
+/*Picture of an item. Source is used to publish a picture from a URL, ID to use one already uploaded.*/
+type Picture struct {
+	ID        string `json:"id,omitempty"`
+	Source    string `json:"source,omitempty"`
+	URL       string `json:"url,omitempty"`
+	SecureURL string `json:"secure_url,omitempty"`
+	Size      string `json:"size,omitempty"`
+	MaxSize   string `json:"max_size,omitempty"`
+}
+
+/*Attribute of an item or a variation, i.e. BRAND. Either ValueID or ValueName has to be set when publishing.*/
+type Attribute struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	ValueID   string `json:"value_id,omitempty"`
+	ValueName string `json:"value_name,omitempty"`
+}
+
+/*Variation of an item, i.e. each color and size of a t-shirt.*/
+type Variation struct {
+	ID                    int64       `json:"id,omitempty"`
+	Price                 float64     `json:"price,omitempty"`
+	AvailableQuantity     int         `json:"available_quantity,omitempty"`
+	SoldQuantity          int         `json:"sold_quantity,omitempty"`
+	AttributeCombinations []Attribute `json:"attribute_combinations,omitempty"`
+	Attributes            []Attribute `json:"attributes,omitempty"`
+	PictureIDs            []string    `json:"picture_ids,omitempty"`
+	SellerCustomField     string      `json:"seller_custom_field,omitempty"`
+}
+
+/*SaleTerm is one of the conditions of the sale, i.e. WARRANTY_TIME.*/
+type SaleTerm struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	ValueID   string `json:"value_id,omitempty"`
+	ValueName string `json:"value_name,omitempty"`
+}
+
+/*Shipping tells how an item is delivered.*/
+type Shipping struct {
+	Mode         string   `json:"mode,omitempty"`
+	LocalPickUp  bool     `json:"local_pick_up,omitempty"`
+	FreeShipping bool     `json:"free_shipping,omitempty"`
+	LogisticType string   `json:"logistic_type,omitempty"`
+	Dimensions   string   `json:"dimensions,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+/*
+ItemUpdate holds the changes Update makes to an item. Only the fields which are set are sent, so the pointers tell
+a field which is left as it is (nil) from one which is set to its zero value, i.e. an AvailableQuantity of 0.
+*/
+type ItemUpdate struct {
+	Title             *string           `json:"title,omitempty"`
+	Price             *float64          `json:"price,omitempty"`
+	AvailableQuantity *int              `json:"available_quantity,omitempty"`
+	VideoID           *string           `json:"video_id,omitempty"`
+	Status            string            `json:"status,omitempty"`
+	Pictures          []Picture         `json:"pictures,omitempty"`
+	Attributes        []Attribute       `json:"attributes,omitempty"`
+	Variations        []VariationUpdate `json:"variations,omitempty"`
+	SaleTerms         []SaleTerm        `json:"sale_terms,omitempty"`
+	Shipping          *ShippingUpdate   `json:"shipping,omitempty"`
+}
+
+/*VariationUpdate holds the changes made to a variation by an ItemUpdate. Variations without ID are created.*/
+type VariationUpdate struct {
+	ID                    int64       `json:"id,omitempty"`
+	Price                 *float64    `json:"price,omitempty"`
+	AvailableQuantity     *int        `json:"available_quantity,omitempty"`
+	AttributeCombinations []Attribute `json:"attribute_combinations,omitempty"`
+	Attributes            []Attribute `json:"attributes,omitempty"`
+	PictureIDs            []string    `json:"picture_ids,omitempty"`
+	SellerCustomField     *string     `json:"seller_custom_field,omitempty"`
+}
+
+/*ShippingUpdate holds the changes made to the shipping of an item by an ItemUpdate.*/
+type ShippingUpdate struct {
+	Mode         string   `json:"mode,omitempty"`
+	LocalPickUp  *bool    `json:"local_pick_up,omitempty"`
+	FreeShipping *bool    `json:"free_shipping,omitempty"`
+	Dimensions   *string  `json:"dimensions,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+/*RelistOptions are the conditions of the new listing created by Relist.*/
+type RelistOptions struct {
+	Price         float64 `json:"price"`
+	Quantity      int     `json:"quantity"`
+	ListingTypeID string  `json:"listing_type_id"`
+}
+
+/*Description of an item. Only plain text is accepted by mercadolibre, HTML descriptions are not supported anymore.*/
+type Description struct {
+	PlainText   string     `json:"plain_text"`
+	Text        string     `json:"text,omitempty"`
+	DateCreated *time.Time `json:"date_created,omitempty"`
+	LastUpdated *time.Time `json:"last_updated,omitempty"`
+}
+
+/*ItemResult is the outcome of each item requested by MultiGet.*/
+type ItemResult struct {
+	ID   string
+	Item *Item
+	Err  error // *APIError when the item could not be retrieved
+}
+
+/*ItemsService gives typed access to the /items resources.*/
+type ItemsService struct {
+	client *Client
+}
+
+/*Items returns the service to manage items on behalf of the user of the client.*/
+func (client *Client) Items() *ItemsService {
+	return &ItemsService{client: client}
+}
+
+/*Get retrieves the item with the given id.*/
+func (service *ItemsService) Get(ctx context.Context, id string) (*Item, error) {
+
+	item := new(Item)
+	if err := service.client.getJSON(ctx, "/items/"+pathID(id), item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+/*
+MultiGet retrieves several items, in as many calls as needed since the API accepts up to 20 ids per call.
+The results are in the same order as ids. An error is returned only when a call fails as a whole.
+*/
+func (service *ItemsService) MultiGet(ctx context.Context, ids []string) ([]ItemResult, error) {
+
+	results := make([]ItemResult, 0, len(ids))
+
+	for start := 0; start < len(ids); start += multiGetSize {
+
+		end := start + multiGetSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var entries []struct {
+			Code int             `json:"code"`
+			Body json.RawMessage `json:"body"`
+		}
+
+		resource := "/items?ids=" + url.QueryEscape(strings.Join(ids[start:end], ","))
+		if err := service.client.getJSON(ctx, resource, &entries); err != nil {
+			return nil, err
+		}
+
+		for i, entry := range entries {
+
+			result := ItemResult{}
+			if start+i < len(ids) {
+				result.ID = ids[start+i]
+			}
+
+			if isSuccess(entry.Code) {
+				result.Item = new(Item)
+				result.Err = json.Unmarshal(entry.Body, result.Item)
+			} else {
+				apiErr := &APIError{StatusCode: entry.Code, Method: http.MethodGet, Endpoint: "/items/" + result.ID, Body: entry.Body}
+				apiErr.parsePayload()
+				result.Err = apiErr
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+/*Create publishes a new item and returns it as created by the API, along with its id.*/
+func (service *ItemsService) Create(ctx context.Context, item Item) (*Item, error) {
+
+	created := new(Item)
+	if err := service.client.doJSON(ctx, http.MethodPost, "/items", item, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+/*Update changes the fields of the item which are set in changes, and returns the updated item.*/
+func (service *ItemsService) Update(ctx context.Context, id string, changes ItemUpdate) (*Item, error) {
+
+	updated := new(Item)
+	if err := service.client.doJSON(ctx, http.MethodPut, "/items/"+pathID(id), changes, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+/*Close finishes the listing. A closed item can not be activated again, but it can be relisted.*/
+func (service *ItemsService) Close(ctx context.Context, id string) (*Item, error) {
+	return service.Update(ctx, id, ItemUpdate{Status: ItemClosed})
+}
+
+/*Pause stops the item from being shown until it is activated.*/
+func (service *ItemsService) Pause(ctx context.Context, id string) (*Item, error) {
+	return service.Update(ctx, id, ItemUpdate{Status: ItemPaused})
+}
+
+/*Activate publishes a paused item again.*/
+func (service *ItemsService) Activate(ctx context.Context, id string) (*Item, error) {
+	return service.Update(ctx, id, ItemUpdate{Status: ItemActive})
+}
+
+/*Relist publishes a closed item again, as a new item which is returned.*/
+func (service *ItemsService) Relist(ctx context.Context, id string, options RelistOptions) (*Item, error) {
+
+	relisted := new(Item)
+	if err := service.client.doJSON(ctx, http.MethodPost, "/items/"+pathID(id)+"/relist", options, relisted); err != nil {
+		return nil, err
+	}
+
+	return relisted, nil
+}
+
+/*Delete deletes an item, which has to be closed first.*/
+func (service *ItemsService) Delete(ctx context.Context, id string) error {
+
+	deleted := struct {
+		Deleted string `json:"deleted"`
+	}{"true"}
+
+	return service.client.doJSON(ctx, http.MethodPut, "/items/"+pathID(id), deleted, nil)
+}
+
+/*GetDescription retrieves the description of an item.*/
+func (service *ItemsService) GetDescription(ctx context.Context, id string) (*Description, error) {
+
+	description := new(Description)
+	if err := service.client.getJSON(ctx, "/items/"+pathID(id)+"/description", description); err != nil {
+		return nil, err
+	}
+
+	return description, nil
+}
+
+/*AddDescription adds a plain text description to an item which was published without one.*/
+func (service *ItemsService) AddDescription(ctx context.Context, id string, plainText string) error {
+	return service.client.doJSON(ctx, http.MethodPost, "/items/"+pathID(id)+"/description", Description{PlainText: plainText}, nil)
+}
+
+/*UpdateDescription replaces the description of an item with the given plain text.*/
+func (service *ItemsService) UpdateDescription(ctx context.Context, id string, plainText string) error {
+	return service.client.doJSON(ctx, http.MethodPut, "/items/"+pathID(id)+"/description", Description{PlainText: plainText}, nil)
+}
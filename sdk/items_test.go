@@ -0,0 +1,281 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_Items_Get_returns_a_typed_item(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/items/MLA123" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id":"MLA123","title":"Item de test","price":10.5,"currency_id":"ARS","available_quantity":3,
+			"status":"active","pictures":[{"id":"123-MLA","url":"http://mla.com/1.jpg"}],
+			"attributes":[{"id":"BRAND","value_name":"Acme"}],"shipping":{"mode":"me2","free_shipping":true},
+			"date_created":"2016-03-01T10:00:00.000Z"}`))
+	})
+	defer closeServer()
+
+	item, err := client.Items().Get(context.Background(), "MLA123")
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if item.ID != "MLA123" || item.Price != 10.5 || item.AvailableQuantity != 3 || item.Status != ItemActive ||
+		len(item.Pictures) != 1 || item.Attributes[0].ValueName != "Acme" || !item.Shipping.FreeShipping || item.DateCreated == nil {
+		log.Printf("Error: unexpected item %+v\n", item)
+		t.FailNow()
+	}
+}
+
+func Test_Items_Get_returns_an_APIError_when_the_item_does_not_exist(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Item with id MLA404 not found","error":"not_found","status":404}`))
+	})
+	defer closeServer()
+
+	item, err := client.Items().Get(context.Background(), "MLA404")
+
+	var apiErr *APIError
+	if item != nil || !IsNotFound(err) || !errors.As(err, &apiErr) || apiErr.Code != "not_found" {
+		log.Printf("Error: a not found APIError was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_Items_MultiGet_splits_the_ids_and_reports_each_item(t *testing.T) {
+
+	var calls []string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		calls = append(calls, r.URL.Query().Get("ids"))
+
+		var entries []string
+		for _, id := range ids {
+			if id == "MLA404" {
+				entries = append(entries, `{"code":404,"body":{"message":"Item with id MLA404 not found","error":"not_found","status":404}}`)
+				continue
+			}
+			entries = append(entries, fmt.Sprintf(`{"code":200,"body":{"id":%q}}`, id))
+		}
+		w.Write([]byte("[" + strings.Join(entries, ",") + "]"))
+	})
+	defer closeServer()
+
+	var ids []string
+	for i := 0; i < 25; i++ {
+		ids = append(ids, fmt.Sprintf("MLA%d", i))
+	}
+	ids[21] = "MLA404"
+
+	results, err := client.Items().MultiGet(context.Background(), ids)
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if len(calls) != 2 || len(strings.Split(calls[0], ",")) != 20 || len(results) != 25 {
+		log.Printf("Error: expected 2 calls and 25 results, obtained %d calls and %d results\n", len(calls), len(results))
+		t.FailNow()
+	}
+
+	if results[24].Item == nil || results[24].Item.ID != "MLA24" || results[24].Err != nil {
+		log.Printf("Error: unexpected result %+v\n", results[24])
+		t.FailNow()
+	}
+
+	if results[21].Item != nil || results[21].ID != "MLA404" || !IsNotFound(results[21].Err) {
+		log.Printf("Error: a not found error was expected for MLA404, obtained %+v\n", results[21])
+		t.FailNow()
+	}
+}
+
+func Test_Items_Update_only_sends_the_fields_which_were_set(t *testing.T) {
+
+	var method, path string
+	var sent map[string]interface{}
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		body, _ := ioutil.ReadAll(r.Body)
+		sent = nil
+		json.Unmarshal(body, &sent)
+		w.Write([]byte(`{"id":"MLA123","price":20,"status":"paused"}`))
+	})
+	defer closeServer()
+
+	price, quantity := 20.0, 5
+	item, err := client.Items().Update(context.Background(), "MLA123", ItemUpdate{Price: &price, AvailableQuantity: &quantity})
+	if err != nil || item.Price != 20 {
+		log.Printf("Error: %v\n", err)
+		t.FailNow()
+	}
+
+	if method != http.MethodPut || path != "/items/MLA123" || len(sent) != 2 || sent["price"] != 20.0 || sent["available_quantity"] != 5.0 {
+		log.Printf("Error: unexpected call %s %s %v\n", method, path, sent)
+		t.FailNow()
+	}
+
+	if _, err := client.Items().Pause(context.Background(), "MLA123"); err != nil || len(sent) != 1 || sent["status"] != ItemPaused {
+		log.Printf("Error: only the status should have been sent, obtained %v %v\n", sent, err)
+		t.FailNow()
+	}
+}
+
+func Test_Items_Update_sends_the_zero_values_which_were_set(t *testing.T) {
+
+	var body string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := ioutil.ReadAll(r.Body)
+		body = string(received)
+		w.Write([]byte(`{"id":"MLA123"}`))
+	})
+	defer closeServer()
+
+	quantity, price, freeShipping := 0, 0.0, false
+
+	changes := ItemUpdate{
+		AvailableQuantity: &quantity,
+		Variations:        []VariationUpdate{{ID: 1, AvailableQuantity: &quantity, Price: &price}},
+		Shipping:          &ShippingUpdate{FreeShipping: &freeShipping},
+	}
+
+	if _, err := client.Items().Update(context.Background(), "MLA123", changes); err != nil {
+		log.Printf("Error: %v\n", err)
+		t.FailNow()
+	}
+
+	expected := `{"available_quantity":0,"variations":[{"id":1,"price":0,"available_quantity":0}],"shipping":{"free_shipping":false}}`
+	if body != expected {
+		log.Printf("Error: expected %s, obtained %s\n", expected, body)
+		t.FailNow()
+	}
+
+	//The shipping options which are not set are left as they are
+	localPickUp := true
+	if _, err := client.Items().Update(context.Background(), "MLA123", ItemUpdate{Shipping: &ShippingUpdate{LocalPickUp: &localPickUp}}); err != nil || body != `{"shipping":{"local_pick_up":true}}` {
+		log.Printf("Error: unexpected update %s %v\n", body, err)
+		t.FailNow()
+	}
+}
+
+func Test_Items_Create_Relist_and_Delete_call_their_resources(t *testing.T) {
+
+	var calls []string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"id":"MLA999"}`))
+	})
+	defer closeServer()
+
+	items := client.Items()
+	ctx := context.Background()
+
+	created, err := items.Create(ctx, Item{Title: "Item de test", CategoryID: "MLA3530", Price: 10, CurrencyID: "ARS"})
+	if err != nil || created.ID != "MLA999" {
+		log.Printf("Error: %v\n", err)
+		t.FailNow()
+	}
+
+	relisted, err := items.Relist(ctx, "MLA123", RelistOptions{Price: 15, Quantity: 1, ListingTypeID: "gold_special"})
+	if err != nil || relisted.ID != "MLA999" {
+		log.Printf("Error: %v\n", err)
+		t.FailNow()
+	}
+
+	if err := items.Delete(ctx, "MLA123"); err != nil {
+		log.Printf("Error: %v\n", err)
+		t.FailNow()
+	}
+
+	expected := []string{
+		`POST /items {"title":"Item de test","category_id":"MLA3530","price":10,"currency_id":"ARS"}`,
+		`POST /items/MLA123/relist {"price":15,"quantity":1,"listing_type_id":"gold_special"}`,
+		`PUT /items/MLA123 {"deleted":"true"}`,
+	}
+
+	for i := range expected {
+		if i >= len(calls) || calls[i] != expected[i] {
+			log.Printf("Error: expected call %s, obtained %v\n", expected[i], calls)
+			t.FailNow()
+		}
+	}
+}
+
+func Test_Items_descriptions_are_sent_as_plain_text(t *testing.T) {
+
+	var calls []string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"text":"","plain_text":"Ray-Ban WAYFARER Gloss Black"}`))
+	})
+	defer closeServer()
+
+	items := client.Items()
+	ctx := context.Background()
+
+	description, err := items.GetDescription(ctx, "MLA123")
+	if err != nil || description.PlainText != "Ray-Ban WAYFARER Gloss Black" {
+		log.Printf("Error: unexpected description %+v %v\n", description, err)
+		t.FailNow()
+	}
+
+	if err := items.AddDescription(ctx, "MLA123", "New"); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if err := items.UpdateDescription(ctx, "MLA123", "Updated"); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	expected := []string{
+		"GET /items/MLA123/description ",
+		`POST /items/MLA123/description {"plain_text":"New"}`,
+		`PUT /items/MLA123/description {"plain_text":"Updated"}`,
+	}
+
+	for i := range expected {
+		if i >= len(calls) || calls[i] != expected[i] {
+			log.Printf("Error: expected call %s, obtained %v\n", expected[i], calls)
+			t.FailNow()
+		}
+	}
+}
@@ -31,6 +31,7 @@ package sdk
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -64,25 +65,33 @@ const (
 	AuthoricationCode = "authorization_code"
 	APIURL            = "https://api.mercadolibre.com"
 	RefreshToken      = "refresh_token"
+	ClientCredentials = "client_credentials"
 )
 
-var publicClient = &Client{apiURL: APIURL, auth: anonymous, httpClient: MeliHTTPClient{}, tokenRefresher: MeliTokenRefresher{}}
-var clientByUser map[string]*Client
-var clientByUserMutex sync.Mutex
 var anonymous = Authorization{}
-var authMutex = &sync.Mutex{}
 
 var debugEnable = false //Set this true if you want to see debug messages
 
 func init() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	clientByUser = make(map[string]*Client)
 }
 
-/*GetAuthURL function returns the URL for the user to authenticate and authorize*/
+/*
+GetAuthURL function returns the URL for the user to authenticate and authorize.
+baseSite is either the id of a site, i.e. MLA, or the base URL of its authorization page, i.e. AuthURLMLA.
+An empty URL is returned for any other baseSite. See AuthURLBuilder, which reports it as an error.
+*/
 func GetAuthURL(clientID int64, baseSite, callback string) string {
 
-	authURL := newAuthorizationURL(baseSite + "/authorization")
+	base, err := authBaseURL(baseSite)
+	if err != nil {
+		if debugEnable {
+			log.Printf("Error %s\n", err)
+		}
+		return ""
+	}
+
+	authURL := newAuthorizationURL(base + "/authorization")
 	authURL.addResponseType("code")
 	authURL.addClientId(clientID)
 	authURL.addRedirectURI(callback)
@@ -93,10 +102,27 @@ func GetAuthURL(clientID int64, baseSite, callback string) string {
 type MeliConfig struct {
 	ClientID       int64
 	UserCode       string
+	CodeVerifier   string // PKCE code_verifier sent along with UserCode. See AuthURLBuilder
 	Secret         string
 	CallBackURL    string
 	HTTPClient     HTTPClient
 	TokenRefresher TokenRefresher
+	RetryPolicy    *RetryPolicy    // Transient errors are not retried when nil
+	RateLimiter    RateLimiter     // Calls are not throttled when nil. It can be shared among several configs
+	TokenStore     TokenStore      // Tokens are only kept in memory when nil
+	UserID         int64           // Used along with TokenStore to build a client from a stored token, when UserCode is empty
+	Registry       *ClientRegistry // Authorized clients are cached in DefaultClientRegistry when nil
+	DisableCache   bool            // When true, clients are not cached and every call to MeliClient builds a new one
+	Clock          Clock           // Used by every expiry and refresh decision. The system clock is used when nil
+	RefreshSkew    time.Duration   // Tokens are refreshed this long before they expire. 60 seconds when zero
+	AuthSite       string          // Site id or base URL of the authorization page given to OnReauthorizationRequired. MLA when empty. See GetAuthURL
+	LoginURL       string          // URL which starts a new authorization, i.e. the one of the sdk/oauth login handler
+
+	// OnReauthorizationRequired is called once the refresh token of a user was rejected with invalid_grant, i.e. because
+	// the user revoked the grant, with the URL the user has to visit to authorize the application again. That is LoginURL
+	// when set. Otherwise it is the authorization page, which carries neither a state nor a PKCE challenge, so the
+	// handler at CallBackURL must not be the sdk/oauth callback, which rejects the authorizations it did not start.
+	OnReauthorizationRequired func(userID int64, authURL string)
 }
 
 /*Meli function returns a Client which can be used to call mercadolibre API.
@@ -128,258 +154,1006 @@ func Meli(clientID int64, userCode string, secret string, callBackURL string) (*
 /**
 This function allows you to be more specific on the config you prefer giving to the sdk Client.
 In case you want to use your own HttpClient or your TokenRefresher policy, you can use the following.
+When the token obtained can not be saved in the TokenStore, the authorized client is returned along with an error
+wrapping ErrTokenNotSaved, since the UserCode can not be exchanged again.
 */
 func MeliClient(config MeliConfig) (*Client, error) {
 
+	return MeliClientContext(context.Background(), config)
+}
+
+/**
+MeliClientContext works as MeliClient, but the authorization_code exchange performed for new users
+is bound to ctx, so it can be cancelled or given a deadline.
+*/
+func MeliClientContext(ctx context.Context, config MeliConfig) (*Client, error) {
+
+	//If userCode is not provided, but the user already authorized the application, the client is built from the stored token.
+	if strings.Compare(config.UserCode, "") == 0 && config.UserID != 0 && config.TokenStore != nil {
+		return storedClient(ctx, config)
+	}
+
 	//If userCode is not provided, then a generic client is returned.
 	//This client can be used only to access public API
 	if strings.Compare(config.UserCode, "") == 0 {
-		return publicClient, nil
+		return newClient(config), nil
 	}
 
 	//If we are here, userCode was provided, so a full client is going to be set up, to allow full access to either private
 	//and public API
-	clientByUserMutex.Lock()
-	defer clientByUserMutex.Unlock()
+	registry := config.registry()
 
 	//The same client is going to be returned if the same applicationId and userCode is provided.
-	key := strconv.FormatInt(config.ClientID, 10) + config.UserCode
+	if registry != nil {
+		cached, done, err := registry.clientForCode(ctx, config.ClientID, config.UserCode)
+		if err != nil {
+			return nil, err
+		}
+		if cached != nil {
+			cached.reconfigure(config)
+			return cached, nil
+		}
+		defer done()
+	}
 
-	var client *Client
-	client = clientByUser[key]
+	client := newClient(config)
 
-	if client == nil {
+	if debugEnable {
+		log.Printf("Building a client: %p for clientid:%d\n", client, config.ClientID)
+	}
 
-		client = &Client{
-			id:             config.ClientID,
-			code:           config.UserCode,
-			secret:         config.Secret,
-			redirectURL:    config.CallBackURL,
-			apiURL:         APIURL,
-			httpClient:     config.HTTPClient,
-			tokenRefresher: config.TokenRefresher,
+	auth, err := client.authorize(ctx)
+
+	if err != nil {
+		if debugEnable {
+			log.Printf("error: %s", err.Error())
+		}
+		if auth == nil {
+			return nil, err
 		}
+	}
+
+	client.setAuthorization(*auth)
+
+	//Clients are cached by the user they act on behalf of, so a user authorizing the application again keeps its client
+	if registry != nil && auth.UserID != 0 {
+		client = registry.add(ClientKey{config.ClientID, auth.UserID}, config.UserCode, client)
+		client.reconfigure(config)
+	}
 
+	return client, err
+}
+
+/*registry returns the registry where the clients built from config are cached, or nil if they are not cached.*/
+func (config MeliConfig) registry() *ClientRegistry {
+
+	if config.DisableCache {
+		return nil
+	}
+
+	if config.Registry != nil {
+		return config.Registry
+	}
+
+	return DefaultClientRegistry
+}
+
+/*
+NewClientFromToken returns a client which uses the given Authorization, previously obtained by your application,
+so the authorization_code exchange is not performed. The tokens are refreshed when needed as usual.
+An Authorization whose ExpiresAt and ReceivedAt are zero is considered expired, so it is refreshed before the first call.
+
+Clients built this way are not cached. Share the returned client instead of building several ones for the same user,
+since mercadolibre invalidates the refresh token once it is used.
+*/
+func NewClientFromToken(config MeliConfig, auth Authorization) (*Client, error) {
+
+	if auth.AccessToken == "" && auth.RefreshToken == "" {
+		return nil, errors.New("the authorization has neither an access token nor a refresh token")
+	}
+
+	client := newClient(config)
+	client.setAuthorization(auth)
+
+	return client, nil
+}
+
+/*
+NewClientFromRefreshToken returns a client whose tokens are obtained by using the given refresh token. See NewClientFromToken.
+When the new tokens can not be saved in the TokenStore, the client is returned along with an error wrapping ErrTokenNotSaved,
+since the refresh token given can not be used anymore.
+*/
+func NewClientFromRefreshToken(config MeliConfig, refreshToken string) (*Client, error) {
+
+	return NewClientFromRefreshTokenContext(context.Background(), config, refreshToken)
+}
+
+/*NewClientFromRefreshTokenContext works as NewClientFromRefreshToken, but the refresh is bound to ctx.*/
+func NewClientFromRefreshTokenContext(ctx context.Context, config MeliConfig, refreshToken string) (*Client, error) {
+
+	if refreshToken == "" {
+		return nil, errors.New("the refresh token is empty")
+	}
+
+	client := newClient(config)
+	client.setAuthorization(Authorization{RefreshToken: refreshToken})
+
+	if err := client.refreshToken(ctx); err != nil {
 		if debugEnable {
-			log.Printf("Building a client: %p for clientid:%d code:%s\n", client, config.ClientID, config.UserCode)
+			log.Printf("Error while refreshing token %s\n", err.Error())
 		}
+		//The refresh token was rotated, so the client is the only one holding the new one
+		if errors.Is(err, ErrTokenNotSaved) {
+			return client, err
+		}
+		return nil, err
+	}
 
-		auth, err := client.authorize()
+	return client, nil
+}
 
-		if err != nil {
-			if debugEnable {
-				log.Printf("error: %s", err.Error())
-			}
-			return nil, err
+/*
+storedClient returns a client for config.UserID, whose Authorization is loaded from config.TokenStore.
+ErrTokenNotFound is returned if the user never authorized the application.
+*/
+func storedClient(ctx context.Context, config MeliConfig) (*Client, error) {
+
+	registry := config.registry()
+
+	if registry != nil {
+		if client, ok := registry.Get(config.ClientID, config.UserID); ok {
+			client.reconfigure(config)
+			return client, nil
 		}
+	}
 
-		clientByUser[key] = client
-		client.auth = *auth
+	auth, err := config.TokenStore.Load(ctx, config.ClientID, config.UserID)
+	if err != nil {
+		if debugEnable {
+			log.Printf("Error while loading the token of user %d: %s", config.UserID, err)
+		}
+		return nil, err
+	}
+
+	client := newClient(config)
+	client.setAuthorization(*auth)
+
+	if registry != nil {
+		client = registry.add(ClientKey{config.ClientID, config.UserID}, "", client)
 	}
 
 	return client, nil
 }
 
+/*
+newClient returns a Client built from config, which is not authorized yet.
+The sdk defaults are used for the HTTPClient and the TokenRefresher when they are not provided.
+*/
+func newClient(config MeliConfig) *Client {
+
+	client := &Client{
+		id:             config.ClientID,
+		code:           config.UserCode,
+		codeVerifier:   config.CodeVerifier,
+		secret:         config.Secret,
+		redirectURL:    config.CallBackURL,
+		apiURL:         APIURL,
+		httpClient:     config.HTTPClient,
+		tokenRefresher: config.TokenRefresher,
+		retryPolicy:    config.RetryPolicy,
+		rateLimiter:    config.RateLimiter,
+		tokenStore:     config.TokenStore,
+		registry:       config.registry(),
+		clock:          config.Clock,
+		refreshSkew:    config.RefreshSkew,
+
+		authSite:         config.AuthSite,
+		loginURL:         config.LoginURL,
+		onReauthRequired: config.OnReauthorizationRequired,
+	}
+
+	if client.clock == nil {
+		client.clock = systemClock{}
+	}
+
+	if client.refreshSkew == 0 {
+		client.refreshSkew = defaultRefreshSkew
+	}
+
+	if client.httpClient == nil {
+		client.httpClient = MeliHTTPClient{}
+	}
+
+	if client.tokenRefresher == nil {
+		client.tokenRefresher = MeliTokenRefresher{}
+	}
+
+	return client
+}
+
 /**
 HTTP Methods
 Given that error handling for all the HTTP Methods is pretty the same, then an interface Callback is define, which is
-going to be called by the handler to execute the different HTTP Methods, then check the response and handle the error
+going to be called by the handler to build the request for the different HTTP Methods. The handler then sends it through
+the client HTTPClient, checks the response and handles the error
 */
 type Callback interface {
-	Call(apiURL string) (*http.Response, error)
+	NewRequest(ctx context.Context, apiURL string) (*http.Request, error)
 }
 
-func httpErrorHandler(client *Client, resource string, httpMethod Callback) (*http.Response, error) {
+func httpErrorHandler(ctx context.Context, client *Client, resource string, httpMethod Callback) (*http.Response, error) {
+
+	apiURL := client.apiURL + resource
 
-	var apiURL *AuthorizationURL
+	var req *http.Request
+	var resp *http.Response
 	var err error
 
-	if apiURL, err = getAuthorizedURL(client, resource); err != nil {
+	for attempt := 1; ; attempt++ {
+
+		if err = client.waitRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		if req, err = newAuthorizedRequest(ctx, client, apiURL, httpMethod); err != nil {
+			return nil, err
+		}
+
+		resp, err = client.getHTTPClient().Do(req)
+
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			client.throttleRateLimit(resp)
+		}
+
+		delay, retry := client.retryPolicy.nextDelay(attempt, req, resp, err)
+		if !retry {
+			break
+		}
+
 		if debugEnable {
-			log.Printf("Error %s", err)
+			log.Printf("Retrying url: %s in %s (attempt %d failed)", redactURL(apiURL), delay, attempt)
+		}
+
+		discardResponse(resp)
+
+		if err = sleep(ctx, delay); err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 
-	var resp *http.Response
-	if resp, err = httpMethod.Call(apiURL.string()); err != nil {
+	if err != nil {
+		err = redactError(err)
 		if debugEnable {
-			log.Printf("Error while calling url: %s \n Error: %s", apiURL.string(), err)
+			log.Printf("Error while calling url: %s \n Error: %s", redactURL(apiURL), err)
 		}
 		return nil, err
 	}
 
+	if !isSuccess(resp.StatusCode) {
+		apiErr := newAPIError(req, resp)
+		if debugEnable {
+			log.Printf("Error while calling url: %s \n Error: %s", redactURL(apiURL), apiErr)
+		}
+		return nil, apiErr
+	}
+
 	return resp, nil
 }
 
+func (client *Client) rateLimitScope() RateLimitScope {
+	return RateLimitScope{ClientID: client.id, UserID: client.knownUserID()}
+}
+
+func (client *Client) waitRateLimit(ctx context.Context) error {
+
+	if client.rateLimiter == nil {
+		return nil
+	}
+
+	return client.rateLimiter.Wait(ctx, client.rateLimitScope())
+}
+
+func (client *Client) throttleRateLimit(resp *http.Response) {
+
+	if client.rateLimiter == nil {
+		return
+	}
+
+	retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"))
+	client.rateLimiter.Throttle(client.rateLimitScope(), retryAfter)
+}
+
+/*
+newAuthorizedRequest builds the request through the callback and sets the access token of the client, refreshing it if needed.
+It is called once per attempt, so a token which expires between retries is refreshed as well.
+*/
+func newAuthorizedRequest(ctx context.Context, client *Client, apiURL string, httpMethod Callback) (*http.Request, error) {
+
+	var accessToken string
+	var err error
+
+	if accessToken, err = getAccessToken(ctx, client); err != nil {
+		if debugEnable {
+			log.Printf("Error %s", err)
+		}
+		return nil, err
+	}
+
+	var req *http.Request
+	if req, err = httpMethod.NewRequest(ctx, apiURL); err != nil {
+		if debugEnable {
+			log.Printf("Error when creating request for url: %s \n Error: %s", redactURL(apiURL), err)
+		}
+		return nil, redactError(err)
+	}
+
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	if key, ok := IdempotencyKey(ctx); ok {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	return req, nil
+}
+
 /*
 HTTP Methods to be called by httpErrorHandler
 */
 type HTTPGet struct {
-	httpClient HTTPClient
 }
 
-func (callback HTTPGet) Call(url string) (*http.Response, error) {
-	return callback.httpClient.Get(url)
+func (callback HTTPGet) NewRequest(ctx context.Context, url string) (*http.Request, error) {
+	return newHTTPRequest(ctx, http.MethodGet, url, "", nil)
 }
 
 type HTTPPost struct {
-	httpClient HTTPClient
-	body       string
+	body string
 }
 
-func (callback HTTPPost) Call(url string) (*http.Response, error) {
-	return callback.httpClient.Post(url, "application/json", bytes.NewReader([]byte(callback.body)))
+func (callback HTTPPost) NewRequest(ctx context.Context, url string) (*http.Request, error) {
+	return newHTTPRequest(ctx, http.MethodPost, url, "application/json", strings.NewReader(callback.body))
 }
 
 type HTTPPut struct {
-	httpClient HTTPClient
-	body       string
+	body string
+}
+
+func (callback HTTPPut) NewRequest(ctx context.Context, url string) (*http.Request, error) {
+	return newHTTPRequest(ctx, http.MethodPut, url, "application/json", strings.NewReader(callback.body))
 }
 
-func (callback HTTPPut) Call(url string) (*http.Response, error) {
-	return callback.httpClient.Put(url, strings.NewReader(callback.body))
+type HTTPPatch struct {
+	body string
+}
+
+func (callback HTTPPatch) NewRequest(ctx context.Context, url string) (*http.Request, error) {
+	return newHTTPRequest(ctx, http.MethodPatch, url, "application/json", strings.NewReader(callback.body))
+}
+
+type HTTPHead struct {
+}
+
+func (callback HTTPHead) NewRequest(ctx context.Context, url string) (*http.Request, error) {
+	return newHTTPRequest(ctx, http.MethodHead, url, "", nil)
 }
 
 type HTTPDelete struct {
-	httpClient HTTPClient
 }
 
-func (callback HTTPDelete) Call(url string) (*http.Response, error) {
-	return callback.httpClient.Delete(url, nil)
+func (callback HTTPDelete) NewRequest(ctx context.Context, url string) (*http.Request, error) {
+	return newHTTPRequest(ctx, http.MethodDelete, url, "", nil)
+}
+
+/*
+HTTPRequest sends a request built by the caller, keeping its method, headers and body.
+Only its URL is replaced by the authorized one.
+*/
+type HTTPRequest struct {
+	request *http.Request
+}
+
+func (callback HTTPRequest) NewRequest(ctx context.Context, apiURL string) (*http.Request, error) {
+
+	finalURL, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req := callback.request.Clone(ctx)
+	req.URL = finalURL
+	req.Host = ""
+
+	if callback.request.GetBody != nil {
+		if req.Body, err = callback.request.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+func newHTTPRequest(ctx context.Context, method string, url string, bodyType string, body io.Reader) (*http.Request, error) {
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if bodyType != "" {
+		req.Header.Set("Content-Type", bodyType)
+	}
+
+	return req, nil
 }
 
 type Client struct {
 	apiURL         string
 	id             int64
-	secret         string
 	code           string
+	codeVerifier   string
 	redirectURL    string
-	auth           Authorization
-	httpClient     HTTPClient
 	tokenRefresher TokenRefresher
+	retryPolicy    *RetryPolicy
+	rateLimiter    RateLimiter
+	tokenStore     TokenStore
+	clock          Clock
+	refreshSkew    time.Duration
+	application    bool // Authorized by the client_credentials grant, on behalf of the application itself
+
+	configMutex sync.RWMutex
+	secret      string          //Guarded by configMutex
+	httpClient  HTTPClient      //Guarded by configMutex
+	registry    *ClientRegistry //Guarded by configMutex, the registry Logout evicts the client from
+
+	authMutex            sync.RWMutex
+	auth                 Authorization //Guarded by authMutex
+	reauthorizationError error         //Guarded by authMutex, set once the refresh token was rejected
+	userID               int64         //Guarded by authMutex, resolved by /users/me when the token did not carry it
+
+	authSite         string
+	loginURL         string
+	onReauthRequired func(userID int64, authURL string)
+
+	refreshMutex sync.Mutex
+	refreshing   *refreshCall //Guarded by refreshMutex, nil when no refresh is in progress
 }
 
+/*refreshCall is a token refresh shared by every goroutine which needs it while it is in progress.*/
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+/*Max time a token refresh can take, since it is not bound to the context of any caller.*/
+const refreshTimeout = 30 * time.Second
+
 /*
 This method returns an Authorization object which contains the needed tokens
 to interact with ML API
 */
-func (client *Client) authorize() (*Authorization, error) {
+func (client *Client) authorize(ctx context.Context) (*Authorization, error) {
 
-	authURL := newAuthorizationURL(client.apiURL + "/oauth/token")
-	authURL.addGrantType(AuthoricationCode)
-	authURL.addClientId(client.id)
-	authURL.addClientSecret(client.secret)
-	authURL.addCode(client.code)
-	authURL.addRedirectURI(client.redirectURL)
+	form := url.Values{}
+	form.Set("grant_type", AuthoricationCode)
+	form.Set("client_id", strconv.FormatInt(client.id, 10))
+	form.Set("client_secret", client.getSecret())
+	form.Set("code", client.code)
+	form.Set("redirect_uri", client.redirectURL)
 
-	var resp *http.Response
-	var err error
-	if resp, err = client.httpClient.Post(authURL.string(), "application/json", *(new(io.Reader))); err != nil {
+	if client.codeVerifier != "" {
+		form.Set("code_verifier", client.codeVerifier)
+	}
+
+	authorization := new(Authorization)
+	if err := client.requestToken(ctx, form, authorization); err != nil {
+		return nil, err
+	}
+
+	//The code can not be exchanged again, so the authorization is kept even if it could not be saved
+	return authorization, client.storeAuthorization(ctx, *authorization)
+}
+
+/*storeAuthorization saves auth in the TokenStore of the client, if it has one. Its errors wrap ErrTokenNotSaved.*/
+func (client *Client) storeAuthorization(ctx context.Context, auth Authorization) error {
+
+	if client.tokenStore == nil {
+		return nil
+	}
+
+	if err := client.tokenStore.Save(ctx, client.id, auth.UserID, auth); err != nil {
 		if debugEnable {
-			log.Printf("Error when posting: %s", err)
+			log.Printf("Error while saving the token of user %d: %s", auth.UserID, err)
 		}
+		return fmt.Errorf("%w: %w", ErrTokenNotSaved, err)
+	}
+
+	return nil
+}
+
+/*
+newTokenRequest returns a POST to the oauth token endpoint. Its params are sent form-encoded in the body,
+so neither secrets nor codes end up in the URL.
+*/
+func newTokenRequest(ctx context.Context, apiURL string, form url.Values) (*http.Request, error) {
+
+	req, err := newHTTPRequest(ctx, http.MethodPost, apiURL+"/oauth/token", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
 		return nil, err
 	}
 
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (client *Client) getSecret() string {
+
+	client.configMutex.RLock()
+	defer client.configMutex.RUnlock()
+
+	return client.secret
+}
+
+func (client *Client) getHTTPClient() HTTPClient {
+
+	client.configMutex.RLock()
+	defer client.configMutex.RUnlock()
+
+	return client.httpClient
+}
+
+func (client *Client) getRegistry() *ClientRegistry {
+
+	client.configMutex.RLock()
+	defer client.configMutex.RUnlock()
+
+	return client.registry
+}
+
+/*reconfigure applies the Secret and the HTTPClient of config to a cached client, since they may have changed since it was built.*/
+func (client *Client) reconfigure(config MeliConfig) {
+
+	client.configMutex.Lock()
+	defer client.configMutex.Unlock()
+
+	if config.Secret != "" {
+		client.secret = config.Secret
+	}
+
+	if config.HTTPClient != nil {
+		client.httpClient = config.HTTPClient
+	}
+}
+
+/*
+requestToken posts form to the oauth token endpoint and decodes the tokens received into auth, which is stamped as received.
+The fields of auth which are not part of the response are kept.
+*/
+func (client *Client) requestToken(ctx context.Context, form url.Values, auth *Authorization) error {
+
+	req, err := newTokenRequest(ctx, client.apiURL, form)
+	if err != nil {
+		return err
+	}
+
+	var resp *http.Response
+	if resp, err = client.getHTTPClient().Do(req); err != nil {
+		err = redactError(err)
+		if debugEnable {
+			log.Printf("Error when posting: %s\n", err)
+		}
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(req, resp)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(fmt.Sprintf("Ups, there was an error: %s", body))
+	if err != nil {
+		return err
 	}
 
-	authorization := new(Authorization)
-	if err := json.Unmarshal(body, authorization); err != nil {
+	if err := json.Unmarshal(body, auth); err != nil {
 		if debugEnable {
-			log.Printf("Error while receiving the authorization %s %s", err.Error(), body)
+			log.Printf("Error while receiving the authorization (%d bytes) %s", len(body), err.Error())
 		}
-		return nil, err
+		return err
+	}
+
+	auth.received(client.now())
+
+	if debugEnable {
+		log.Printf("auth received at: %d expires at: %s\n", auth.ReceivedAt, auth.ExpiresAt)
+	}
+
+	return nil
+}
+
+func (client *Client) refreshToken(ctx context.Context) error {
+	return client.tokenRefresher.RefreshToken(ctx, client)
+}
+
+/*
+Authorization returns the tokens currently used by the client. Since they are refreshed automatically,
+call it again (or use a TokenStore) whenever they need to be persisted.
+*/
+func (client *Client) Authorization() Authorization {
+
+	client.authMutex.RLock()
+	defer client.authMutex.RUnlock()
+
+	return client.auth
+}
+
+func (client *Client) setAuthorization(auth Authorization) {
+
+	client.authMutex.Lock()
+	defer client.authMutex.Unlock()
+
+	client.auth = auth
+	client.reauthorizationError = nil
+
+	if auth == anonymous {
+		client.userID = 0
+	}
+}
+
+/*
+NeedsReauthorization reports whether the refresh token of the client was rejected, so the user has to authorize
+the application again. The client does not try to refresh it anymore, and its calls fail with ErrReauthorizationRequired.
+*/
+func (client *Client) NeedsReauthorization() bool {
+	return client.reauthorizationErr() != nil
+}
+
+func (client *Client) reauthorizationErr() error {
+
+	client.authMutex.RLock()
+	defer client.authMutex.RUnlock()
+
+	return client.reauthorizationError
+}
+
+/*requireReauthorization marks the client as needing reauthorization because of err, and reports whether it was not marked yet.*/
+func (client *Client) requireReauthorization(err error) bool {
+
+	client.authMutex.Lock()
+	defer client.authMutex.Unlock()
+
+	if client.reauthorizationError != nil {
+		return false
 	}
 
-	authorization.ReceivedAt = time.Now().Unix()
-	return authorization, nil
+	client.reauthorizationError = fmt.Errorf("%w: %w", ErrReauthorizationRequired, err)
+	return true
 }
 
-func (client *Client) refreshToken() error {
-	return client.tokenRefresher.RefreshToken(client)
+/*notifyReauthorizationRequired calls the OnReauthorizationRequired hook with a new URL to authorize the application.*/
+func (client *Client) notifyReauthorizationRequired() {
+
+	if client.onReauthRequired == nil {
+		return
+	}
+
+	if client.loginURL != "" {
+		client.onReauthRequired(client.knownUserID(), client.loginURL)
+		return
+	}
+
+	site := client.authSite
+	if site == "" {
+		site = "MLA"
+	}
+
+	client.onReauthRequired(client.knownUserID(), GetAuthURL(client.id, site, client.redirectURL))
+}
+
+/*
+refreshTokenOnce refreshes the token of the client when due reports it has to be, making sure only one refresh is in progress at a time.
+Goroutines arriving while a refresh is in progress wait for it and receive its result, including its error.
+Each caller stops waiting as soon as its own ctx is done, but the refresh itself goes on for the rest of them.
+*/
+func (client *Client) refreshTokenOnce(ctx context.Context, due func(Authorization) bool) error {
+
+	client.refreshMutex.Lock()
+
+	call := client.refreshing
+
+	if call == nil {
+
+		//The token may have been refreshed while this goroutine was waiting for the lock
+		if !due(client.Authorization()) {
+			client.refreshMutex.Unlock()
+			return nil
+		}
+
+		call = &refreshCall{done: make(chan struct{})}
+		client.refreshing = call
+
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+
+		go func() {
+			defer cancel()
+			err := client.refreshToken(refreshCtx)
+
+			//A rejected refresh token will not be accepted later, so it is not sent again
+			notify := IsInvalidGrant(err) && !client.application && client.requireReauthorization(err)
+
+			client.refreshMutex.Lock()
+			call.err = err
+			client.refreshing = nil
+			client.refreshMutex.Unlock()
+
+			close(call.done)
+
+			if notify {
+				client.notifyReauthorizationRequired()
+			}
+		}()
+	}
+
+	client.refreshMutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (client *Client) Get(resourcePath string) (*http.Response, error) {
 
-	return httpErrorHandler(client, resourcePath, HTTPGet{httpClient: client.httpClient})
+	return client.GetContext(context.Background(), resourcePath)
 }
 
 func (client *Client) Post(resourcePath string, body string) (*http.Response, error) {
 
-	return httpErrorHandler(client, resourcePath, HTTPPost{httpClient: client.httpClient, body: body})
+	return client.PostContext(context.Background(), resourcePath, body)
 }
 
 func (client *Client) Put(resourcePath string, body string) (*http.Response, error) {
 
-	return httpErrorHandler(client, resourcePath, HTTPPut{httpClient: client.httpClient, body: body})
+	return client.PutContext(context.Background(), resourcePath, body)
 }
 
 func (client *Client) Delete(resourcePath string) (*http.Response, error) {
 
-	return httpErrorHandler(client, resourcePath, HTTPDelete{httpClient: client.httpClient})
+	return client.DeleteContext(context.Background(), resourcePath)
+}
+
+/*
+The Context variants bound the whole call to ctx: once ctx is cancelled or its deadline is exceeded,
+both the token refresh (if one is needed) and the request itself are aborted and ctx's error is returned.
+*/
+func (client *Client) GetContext(ctx context.Context, resourcePath string) (*http.Response, error) {
+
+	return httpErrorHandler(ctx, client, resourcePath, HTTPGet{})
+}
+
+func (client *Client) PostContext(ctx context.Context, resourcePath string, body string) (*http.Response, error) {
+
+	return httpErrorHandler(ctx, client, resourcePath, HTTPPost{body: body})
+}
+
+func (client *Client) PutContext(ctx context.Context, resourcePath string, body string) (*http.Response, error) {
+
+	return httpErrorHandler(ctx, client, resourcePath, HTTPPut{body: body})
+}
+
+func (client *Client) DeleteContext(ctx context.Context, resourcePath string) (*http.Response, error) {
+
+	return httpErrorHandler(ctx, client, resourcePath, HTTPDelete{})
+}
+
+func (client *Client) Patch(resourcePath string, body string) (*http.Response, error) {
+
+	return client.PatchContext(context.Background(), resourcePath, body)
+}
+
+func (client *Client) PatchContext(ctx context.Context, resourcePath string, body string) (*http.Response, error) {
+
+	return httpErrorHandler(ctx, client, resourcePath, HTTPPatch{body: body})
+}
+
+func (client *Client) Head(resourcePath string) (*http.Response, error) {
+
+	return client.HeadContext(context.Background(), resourcePath)
+}
+
+func (client *Client) HeadContext(ctx context.Context, resourcePath string) (*http.Response, error) {
+
+	return httpErrorHandler(ctx, client, resourcePath, HTTPHead{})
+}
+
+/*
+Do sends a request built by the caller, which allows using custom headers or bodies (i.e. multipart uploads).
+The request URL must be relative to the API, i.e. built with http.NewRequestWithContext(ctx, http.MethodPatch, "/items/MLA123", body).
+Authorization and token refreshing are handled the same way they are for the other HTTP Methods.
+In order to be able to resend the request, its body should be created with http.NewRequest from a *bytes.Buffer, *bytes.Reader or *strings.Reader.
+*/
+func (client *Client) Do(req *http.Request) (*http.Response, error) {
+
+	return httpErrorHandler(req.Context(), client, req.URL.RequestURI(), HTTPRequest{request: req})
 }
 
-func (client Client) IsAuthorized() bool {
+func (client *Client) IsAuthorized() bool {
 
-	return (client.auth != anonymous)
+	return (client.Authorization() != anonymous)
 }
 
 /*
-This method returns the URL + Token to be used by each HTTP request.
+This method returns the Token to be sent by each HTTP request, or an empty string for anonymous clients.
 If Token needs to be refreshed, then this method will send a POST to ML API to refresh it.
 */
-func getAuthorizedURL(client *Client, resourcePath string) (*AuthorizationURL, error) {
+func getAccessToken(ctx context.Context, client *Client) (string, error) {
 
-	finalURL := newAuthorizationURL(client.apiURL + resourcePath)
-	var err error
+	auth := client.Authorization()
+
+	if auth == anonymous {
+		return "", nil
+	}
 
-	if client.auth != anonymous {
+	if client.isExpired(auth) {
 
-		authMutex.Lock()
+		if err := client.reauthorizationErr(); err != nil {
+			return "", err
+		}
 
-		if client.auth.isExpired() {
+		if debugEnable {
+			log.Printf("Token has expired....Refreshing it...\n")
+		}
 
+		if err := client.refreshTokenOnce(ctx, client.isExpired); err != nil && !errors.Is(err, ErrTokenNotSaved) {
 			if debugEnable {
-				log.Printf("Token has expired....Refreshing it...\n")
+				log.Printf("Error while refreshing token %s\n", err.Error())
 			}
+			if reauthErr := client.reauthorizationErr(); reauthErr != nil {
+				return "", reauthErr
+			}
+			return "", err
+		}
 
-			err := client.refreshToken()
+		auth = client.Authorization()
+	}
 
-			if err != nil {
-				if debugEnable {
-					log.Printf("Error while refreshing token %s\n", err.Error())
-				}
-				return nil, err
-			}
+	return auth.AccessToken, nil
+}
+
+var sensitiveParams = []string{"access_token", "client_secret", "code", "code_verifier", "refresh_token"}
+
+/*
+redactURL returns rawURL with the value of any credential found in its query string replaced,
+so it can be safely logged or returned within an error.
+*/
+func redactURL(rawURL string) string {
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	redacted := false
+
+	for _, param := range sensitiveParams {
+		if query.Get(param) != "" {
+			query.Set(param, "REDACTED")
+			redacted = true
 		}
+	}
+
+	if !redacted {
+		return rawURL
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+/*redactError removes credentials from the URL reported by errors returned by the http package.*/
+func redactError(err error) error {
 
-		authMutex.Unlock()
-		finalURL.addAccessToken(client.auth.AccessToken)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &url.Error{Op: urlErr.Op, URL: redactURL(urlErr.URL), Err: urlErr.Err}
 	}
 
-	return finalURL, err
+	return err
 }
 
 type Authorization struct {
-	AccessToken  string `json:"access_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int16  `json:"expires_in"`
-	ReceivedAt   int64
-	RefreshToken string `json:"refresh_token"`
-	Scope        string `json:"scope"`
+	AccessToken  string        `json:"access_token"`
+	TokenType    string        `json:"token_type"`
+	ExpiresIn    time.Duration `json:"-"` // Encoded in seconds as expires_in, as sent by the API
+	ExpiresAt    time.Time     `json:"expires_at"`
+	ReceivedAt   int64         // Unix time
+	RefreshToken string        `json:"refresh_token"`
+	Scope        string        `json:"scope"`
+	UserID       int64         `json:"user_id"`
 }
 
-func (auth Authorization) isExpired() bool {
+/*plainAuthorization has the fields of Authorization but not its methods, so it can be encoded by the default encoder.*/
+type plainAuthorization Authorization
+
+func (auth Authorization) MarshalJSON() ([]byte, error) {
+
+	return json.Marshal(struct {
+		plainAuthorization
+		ExpiresIn int64 `json:"expires_in"`
+	}{plainAuthorization(auth), int64(auth.ExpiresIn / time.Second)})
+}
+
+func (auth *Authorization) UnmarshalJSON(data []byte) error {
+
+	decoded := struct {
+		*plainAuthorization
+		ExpiresIn *int64 `json:"expires_in"`
+	}{plainAuthorization: (*plainAuthorization)(auth)}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.ExpiresIn != nil {
+		auth.ExpiresIn = time.Duration(*decoded.ExpiresIn) * time.Second
+	}
+
+	return nil
+}
+
+/*received stamps the Authorization as received at now.*/
+func (auth *Authorization) received(now time.Time) {
+	auth.ReceivedAt = now.Unix()
+	auth.ExpiresAt = now.Add(auth.ExpiresIn)
+}
+
+/*expiresAt returns when the access token expires. Authorizations stored before ExpiresAt existed only have ReceivedAt.*/
+func (auth Authorization) expiresAt() time.Time {
+
+	if !auth.ExpiresAt.IsZero() {
+		return auth.ExpiresAt
+	}
+
+	return time.Unix(auth.ReceivedAt, 0).Add(auth.ExpiresIn)
+}
+
+/*
+Clock tells the time to the client. It allows simulating the expiry of the tokens in tests.
+*/
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+const defaultRefreshSkew = 60 * time.Second
+
+func (client *Client) now() time.Time {
+
+	if client.clock == nil {
+		return time.Now()
+	}
+
+	return client.clock.Now()
+}
+
+/*isExpired reports whether the access token expires within the refresh skew of the client.*/
+func (client *Client) isExpired(auth Authorization) bool {
+
 	if debugEnable {
-		log.Printf("received at:%d expires in: %d\n", auth.ReceivedAt, auth.ExpiresIn)
+		log.Printf("received at:%d expires at: %s\n", auth.ReceivedAt, auth.expiresAt())
 	}
-	return ((auth.ReceivedAt + int64(auth.ExpiresIn)) <= (time.Now().Unix() + 60))
+
+	return !auth.expiresAt().After(client.now().Add(client.refreshSkew))
 }
 
 /*
@@ -398,28 +1172,21 @@ func (u *AuthorizationURL) addClientId(value int64) {
 	u.add("client_id=" + strconv.FormatInt(value, 10))
 }
 
-func (u *AuthorizationURL) addClientSecret(value string) {
-	u.add("client_secret=" + url.QueryEscape(value))
-}
-
-func (u *AuthorizationURL) addCode(value string) {
-	u.add("code=" + url.QueryEscape(value))
-}
-
 func (u *AuthorizationURL) addRedirectURI(uri string) {
 	u.add("redirect_uri=" + url.QueryEscape(uri))
 }
 
-func (u *AuthorizationURL) addRefreshToken(t string) {
-	u.add("refresh_token=" + url.QueryEscape(t))
-}
-
 func (u *AuthorizationURL) addResponseType(value string) {
 	u.add("response_type=" + url.QueryEscape(value))
 }
 
-func (u *AuthorizationURL) addAccessToken(t string) {
-	u.add("access_token=" + url.QueryEscape(t))
+func (u *AuthorizationURL) addState(value string) {
+	u.add("state=" + url.QueryEscape(value))
+}
+
+func (u *AuthorizationURL) addCodeChallenge(challenge string, method string) {
+	u.add("code_challenge=" + url.QueryEscape(challenge))
+	u.add("code_challenge_method=" + url.QueryEscape(method))
 }
 
 func (u *AuthorizationURL) string() string {
@@ -445,53 +1212,174 @@ func newAuthorizationURL(baseURL string) *AuthorizationURL {
 
 /**
 This interface allows you to change or mock the way Meli client make HTTP Requests.
+It is satisfied by *http.Client, so any configured client (transport, proxy, timeouts) can be used directly.
 */
 type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+/**HTTPClientFunc allows an ordinary function to be used as HTTPClient.*/
+type HTTPClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f HTTPClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+/**
+VerbHTTPClient is a verb based HTTPClient whose methods receive the context of each call.
+Use AdaptHTTPClient to keep using an existing implementation while migrating it to HTTPClient.
+The context received by each method must be honored, so calls can be cancelled by the caller.
+Implementations of the interface of the released versions, without contexts, are adapted by AdaptLegacyHTTPClient.
+
+Deprecated: implement HTTPClient instead, only GET, POST, PUT and DELETE requests can be sent through this interface.
+*/
+type VerbHTTPClient interface {
+	Get(ctx context.Context, url string) (*http.Response, error)
+	Post(ctx context.Context, url string, bodyType string, body io.Reader) (*http.Response, error)
+	Put(ctx context.Context, url string, body io.Reader) (*http.Response, error)
+	Delete(ctx context.Context, url string, body io.Reader) (*http.Response, error)
+}
+
+/**
+AdaptHTTPClient returns an HTTPClient which sends every request through the given VerbHTTPClient.
+The access token is sent as the access_token parameter, and requests carrying headers the verb methods can not send fail.
+*/
+func AdaptHTTPClient(httpClient VerbHTTPClient) HTTPClient {
+	return verbHTTPClientAdapter{httpClient: httpClient}
+}
+
+type verbHTTPClientAdapter struct {
+	httpClient VerbHTTPClient
+}
+
+func (adapter verbHTTPClientAdapter) Do(req *http.Request) (*http.Response, error) {
+
+	var body io.Reader
+	if req.Body != nil && req.Body != http.NoBody {
+		body = req.Body
+	}
+
+	url, err := adapter.url(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+
+	switch req.Method {
+	case http.MethodGet:
+		return adapter.httpClient.Get(ctx, url)
+	case http.MethodPost:
+		return adapter.httpClient.Post(ctx, url, req.Header.Get("Content-Type"), body)
+	case http.MethodPut:
+		return adapter.httpClient.Put(ctx, url, body)
+	case http.MethodDelete:
+		return adapter.httpClient.Delete(ctx, url, body)
+	}
+
+	return nil, fmt.Errorf("%s requests are not supported by %T", req.Method, adapter.httpClient)
+}
+
+/*
+url returns the URL of req, carrying its bearer token as the access_token parameter, since the verb methods can not send headers.
+Requests with other headers, besides the Content-Type of a POST and Accept, are rejected instead of being sent without them.
+*/
+func (adapter verbHTTPClientAdapter) url(req *http.Request) (string, error) {
+
+	target := *req.URL
+
+	for name := range req.Header {
+		switch name {
+		case "Content-Type", "Accept":
+		case "Authorization":
+			token := strings.TrimPrefix(req.Header.Get(name), "Bearer ")
+			if token == req.Header.Get(name) {
+				return "", fmt.Errorf("the %s header can only carry a bearer token when sent by %T", name, adapter.httpClient)
+			}
+			query := target.Query()
+			query.Set("access_token", token)
+			target.RawQuery = query.Encode()
+		default:
+			return "", fmt.Errorf("the %s header can not be sent by %T", name, adapter.httpClient)
+		}
+	}
+
+	return target.String(), nil
+}
+
+/**
+LegacyHTTPClient is the HTTPClient interface of the released versions of the sdk, whose methods do not receive a context.
+Use AdaptLegacyHTTPClient to keep using an existing implementation (i.e. a mock) while migrating it to HTTPClient.
+
+Deprecated: implement HTTPClient instead. Calls sent through this interface can not be cancelled once dispatched.
+*/
+type LegacyHTTPClient interface {
 	Get(url string) (*http.Response, error)
 	Post(url string, bodyType string, body io.Reader) (*http.Response, error)
 	Put(url string, body io.Reader) (*http.Response, error)
 	Delete(url string, body io.Reader) (*http.Response, error)
 }
 
-type MeliHTTPClient struct {
+/**AdaptLegacyHTTPClient returns an HTTPClient which sends every request through the given LegacyHTTPClient. See AdaptHTTPClient.*/
+func AdaptLegacyHTTPClient(httpClient LegacyHTTPClient) HTTPClient {
+	return verbHTTPClientAdapter{httpClient: legacyHTTPClientAdapter{httpClient: httpClient}}
 }
 
-func (httpClient MeliHTTPClient) Get(url string) (*http.Response, error) {
-	return http.Get(url)
+/*legacyHTTPClientAdapter turns a LegacyHTTPClient into a VerbHTTPClient, which checks the context before each call.*/
+type legacyHTTPClientAdapter struct {
+	httpClient LegacyHTTPClient
 }
 
-func (httpClient MeliHTTPClient) Post(url string, bodyType string, body io.Reader) (*http.Response, error) {
-
-	return http.Post(url, bodyType, body)
+func (adapter legacyHTTPClientAdapter) Get(ctx context.Context, url string) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return adapter.httpClient.Get(url)
 }
 
-func (httpClient MeliHTTPClient) Put(url string, body io.Reader) (*http.Response, error) {
-
-	return httpClient.executeHTTPRequest(http.MethodPut, url, body)
+func (adapter legacyHTTPClientAdapter) Post(ctx context.Context, url string, bodyType string, body io.Reader) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return adapter.httpClient.Post(url, bodyType, body)
 }
 
-func (httpClient MeliHTTPClient) Delete(url string, body io.Reader) (*http.Response, error) {
+func (adapter legacyHTTPClientAdapter) Put(ctx context.Context, url string, body io.Reader) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return adapter.httpClient.Put(url, body)
+}
 
-	return httpClient.executeHTTPRequest(http.MethodDelete, url, body)
+func (adapter legacyHTTPClientAdapter) Delete(ctx context.Context, url string, body io.Reader) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return adapter.httpClient.Delete(url, body)
+}
 
+/**
+MeliHTTPClient is the default HTTPClient. It sends the requests through Client, or through
+a client with a default timeout when Client is nil.
+*/
+type MeliHTTPClient struct {
+	Client *http.Client
 }
 
-func (httpClient MeliHTTPClient) executeHTTPRequest(method string, url string, body io.Reader) (*http.Response, error) {
+var defaultHTTPClient = &http.Client{Timeout: 60 * time.Second}
 
-	req, err := http.NewRequest(method, url, body)
+func (httpClient MeliHTTPClient) Do(req *http.Request) (*http.Response, error) {
 
-	if err != nil {
-		if debugEnable {
-			log.Printf("Error when creating %s request %s.", method, err.Error())
-		}
-		return nil, err
+	client := httpClient.Client
+	if client == nil {
+		client = defaultHTTPClient
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 
 	if err != nil {
 		if debugEnable {
-			log.Printf("Error while calling url: %s\n Error: %s", url, err.Error())
+			log.Printf("Error while calling url: %s\n Error: %s", redactURL(req.URL.String()), redactError(err))
 		}
 		return nil, err
 	}
@@ -501,7 +1389,7 @@ func (httpClient MeliHTTPClient) executeHTTPRequest(method string, url string, b
 
 /**TokenRefresher is an interface which allows you to implement your own authentication/authorization mechanism.*/
 type TokenRefresher interface {
-	RefreshToken(*Client) error
+	RefreshToken(context.Context, *Client) error
 }
 
 /**MeliTokenRefresher implements ToeknRefresher interface.
@@ -512,44 +1400,24 @@ type MeliTokenRefresher struct {
 }
 
 /**RefreshToken is a method which has side effects. This one, alters the token that is within the client.
-Every time this method is called some locking mechanism has to be used to avoid concurrency problems when client param is modified.
+The client guards its token, so it is safe to call it concurrently, although the sdk only runs one refresh per client at a time.
 */
-func (refresher MeliTokenRefresher) RefreshToken(client *Client) error {
-
-	authorizationURL := newAuthorizationURL(client.apiURL + "/oauth/token")
-	authorizationURL.addGrantType(RefreshToken)
-	authorizationURL.addClientId(client.id)
-	authorizationURL.addClientSecret(client.secret)
-	authorizationURL.addRefreshToken(client.auth.RefreshToken)
-
-	var resp *http.Response
-	var err error
+func (refresher MeliTokenRefresher) RefreshToken(ctx context.Context, client *Client) error {
 
-	if resp, err = client.httpClient.Post(authorizationURL.string(), "application/json", *(new(io.Reader))); err != nil {
-		if debugEnable {
-			log.Printf("Error: %s\n", err.Error())
-		}
-		return err
-	}
+	auth := client.Authorization()
 
-	if resp.StatusCode != http.StatusOK {
-		return errors.New("Refreshing token returned status code " + resp.Status)
-	}
+	form := url.Values{}
+	form.Set("grant_type", RefreshToken)
+	form.Set("client_id", strconv.FormatInt(client.id, 10))
+	form.Set("client_secret", client.getSecret())
+	form.Set("refresh_token", auth.RefreshToken)
 
-	body, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-
-	if err := json.Unmarshal(body, &(client.auth)); err != nil {
-		if debugEnable {
-			log.Printf("Error while receiving the authorization %s %s", err.Error(), body)
-		}
+	//The refresh token is kept if the response does not include a new one
+	if err := client.requestToken(ctx, form, &auth); err != nil {
 		return err
 	}
 
-	client.auth.ReceivedAt = time.Now().Unix()
+	client.setAuthorization(auth)
 
-	if debugEnable {
-		log.Printf("auth received at: %d expires in:%d\n", client.auth.ReceivedAt, client.auth.ExpiresIn)
-	}
-	return nil
+	return client.storeAuthorization(ctx, auth)
 }
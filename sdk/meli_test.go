@@ -18,16 +18,22 @@ package sdk
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 const (
@@ -35,6 +41,7 @@ const (
 	CLIENT_ID     = 123456
 	CLIENT_SECRET = "client secret"
 	USER_CODE     = "valid code with refresh token"
+	TEST_USER_ID  = "214509008"
 )
 
 func Test_URL_for_authentication_is_properly_returned(t *testing.T) {
@@ -92,7 +99,7 @@ func Test_That_An_Error_Is_Returned_When_Authentication_Fails(t *testing.T) {
 		UserCode:       "NEW_CODE",
 		Secret:         CLIENT_SECRET,
 		CallBackURL:    "http://www.example.com",
-		HTTPClient:     MockHttpClientPostFailure{},
+		HTTPClient:     AdaptHTTPClient(MockHttpClientPostFailure{}),
 		TokenRefresher: MockTockenRefresher{},
 	}
 
@@ -123,10 +130,10 @@ func Test_That_MeliTokenRefresher_Returns_An_Error_When_Posting_Authorization_Fa
 		t.FailNow()
 	}
 
-	client.httpClient = MockHttpClientPostFailure{}
+	client.httpClient = AdaptHTTPClient(MockHttpClientPostFailure{})
 
 	tokenRefresher := MeliTokenRefresher{}
-	error = tokenRefresher.RefreshToken(client)
+	error = tokenRefresher.RefreshToken(context.Background(), client)
 
 	if error == nil {
 		log.Printf("Error: An error should have been received.")
@@ -153,19 +160,19 @@ func Test_MeliTokenRefresher_Returns_An_Error_When_Authorization_Returns_A_HTTP_
 		t.FailNow()
 	}
 
-	client.httpClient = MockHttpClientPostNonOKStatusCode{}
+	client.httpClient = AdaptHTTPClient(MockHttpClientPostNonOKStatusCode{})
 
 	tokenRefresher := MeliTokenRefresher{}
-	error = tokenRefresher.RefreshToken(client)
+	error = tokenRefresher.RefreshToken(context.Background(), client)
 
 	if error == nil {
 		log.Printf("Error: An error should not have been received.")
 		t.FailNow()
 	}
 
-	//TODO: Please..DO NOT COMPARE errors by using strings. Fix this up.
-	if strings.Compare(fmt.Sprintf("%s", error.Error()), "Refreshing token returned status code ") != 0 {
-		log.Printf("Error: An error should have been received.")
+	var apiErr *APIError
+	if !errors.As(error, &apiErr) || apiErr.Endpoint != "/oauth/token" {
+		log.Printf("Error: An APIError should have been received.")
 		t.FailNow()
 	}
 
@@ -205,6 +212,191 @@ func Test_Return_Authorized_TRUE_When_Client_Is_Authorized(t *testing.T) {
 	}
 }
 
+func Test_Client_Is_Built_From_An_Existing_Authorization(t *testing.T) {
+
+	config := MeliConfig{ClientID: CLIENT_ID, Secret: CLIENT_SECRET, HTTPClient: MockHttpClient{}}
+	auth := Authorization{AccessToken: "valid token", RefreshToken: "valid refresh token", ExpiresIn: 10800 * time.Second, ReceivedAt: time.Now().Unix()}
+
+	client, err := NewClientFromToken(config, auth)
+
+	if err != nil || !client.IsAuthorized() || client.Authorization() != auth {
+		log.Printf("Error: the client should use the given authorization. error: %v\n", err)
+		t.FailNow()
+	}
+
+	if _, err := NewClientFromToken(config, Authorization{}); err == nil {
+		log.Printf("Error: an empty authorization should have been rejected\n")
+		t.FailNow()
+	}
+}
+
+func Test_Client_Is_Built_From_A_Refresh_Token(t *testing.T) {
+
+	config := MeliConfig{ClientID: CLIENT_ID, Secret: CLIENT_SECRET, HTTPClient: MockHttpClient{}}
+
+	client, err := NewClientFromRefreshToken(config, "valid refresh token")
+
+	if err != nil || client.Authorization().AccessToken != "valid token" || client.isExpired(client.Authorization()) {
+		log.Printf("Error: the token should have been refreshed. error: %v\n", err)
+		t.FailNow()
+	}
+
+	resp, err := client.Post("/items", "{\"foo\":\"bar\"}")
+
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		log.Printf("Error: the client should be able to access the private API. error: %v\n", err)
+		t.FailNow()
+	}
+
+	if _, err := NewClientFromRefreshToken(config, ""); err == nil {
+		log.Printf("Error: an empty refresh token should have been rejected\n")
+		t.FailNow()
+	}
+}
+
+func Test_Token_Expiry_Follows_The_Clock_And_The_Refresh_Skew(t *testing.T) {
+
+	clock := &MockClock{now: time.Now()}
+	refresher := &MockCountingTokenRefresher{}
+
+	config := MeliConfig{ClientID: CLIENT_ID, Secret: CLIENT_SECRET, HTTPClient: MockHttpClient{}, TokenRefresher: refresher, Clock: clock, RefreshSkew: 5 * time.Minute}
+	auth := Authorization{AccessToken: "valid token", RefreshToken: "valid refresh token", ExpiresIn: 3 * time.Hour}
+	auth.received(clock.Now())
+
+	client, _ := NewClientFromToken(config, auth)
+
+	clock.Advance(3*time.Hour - 6*time.Minute)
+
+	if _, err := client.Get("/sites"); err != nil || atomic.LoadInt32(&refresher.calls) != 0 {
+		log.Printf("Error: the token should not have been refreshed yet. error: %v\n", err)
+		t.FailNow()
+	}
+
+	clock.Advance(2 * time.Minute)
+
+	if _, err := client.Get("/sites"); err != nil || atomic.LoadInt32(&refresher.calls) != 1 {
+		log.Printf("Error: the token should have been refreshed within the skew. error: %v\n", err)
+		t.FailNow()
+	}
+
+	if expiresAt := client.Authorization().ExpiresAt; !expiresAt.Equal(clock.Now().Add(3 * time.Hour)) {
+		log.Printf("Error: the new token should expire 3 hours after the clock time, obtained %s\n", expiresAt)
+		t.FailNow()
+	}
+}
+
+func Test_Authorization_Encodes_ExpiresIn_In_Seconds(t *testing.T) {
+
+	var auth Authorization
+
+	if err := json.Unmarshal([]byte(`{"access_token":"valid token","expires_in":86400}`), &auth); err != nil || auth.ExpiresIn != 24*time.Hour {
+		log.Printf("Error: expires_in should have been decoded as seconds, obtained %s %v\n", auth.ExpiresIn, err)
+		t.FailNow()
+	}
+
+	auth.received(time.Unix(1000, 0))
+	encoded, _ := json.Marshal(auth)
+
+	var decoded Authorization
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.ExpiresIn != auth.ExpiresIn || !decoded.ExpiresAt.Equal(auth.ExpiresAt) ||
+		!strings.Contains(string(encoded), `"expires_in":86400`) {
+		log.Printf("Error: unexpected encoding %s %v\n", encoded, err)
+		t.FailNow()
+	}
+}
+
+func Test_A_Rejected_Refresh_Token_Is_Not_Sent_Again_And_Reauthorization_Is_Requested(t *testing.T) {
+
+	var refreshes int32
+	reauthorize := make(chan string, 2)
+
+	config := MeliConfig{
+		ClientID:    CLIENT_ID,
+		Secret:      CLIENT_SECRET,
+		CallBackURL: "http://www.example.com",
+		AuthSite:    "MLB",
+		HTTPClient: HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/oauth/token") {
+				atomic.AddInt32(&refreshes, 1)
+				return &http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(strings.NewReader(`{"error":"invalid_grant"}`))}, nil
+			}
+			return MockHttpClient{}.Do(req)
+		}),
+		OnReauthorizationRequired: func(userID int64, authURL string) {
+			reauthorize <- fmt.Sprintf("%d %s", userID, authURL)
+		},
+	}
+
+	client, _ := NewClientFromToken(config, Authorization{AccessToken: "expired token", RefreshToken: "revoked refresh token", UserID: 214509008})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get("/sites"); !errors.Is(err, ErrReauthorizationRequired) || !IsInvalidGrant(err) {
+			log.Printf("Error: ErrReauthorizationRequired was expected, obtained %v\n", err)
+			t.FailNow()
+		}
+	}
+
+	if atomic.LoadInt32(&refreshes) != 1 || !client.NeedsReauthorization() {
+		log.Printf("Error: the rejected refresh token should have been sent once, it was sent %d times\n", refreshes)
+		t.FailNow()
+	}
+
+	select {
+	case call := <-reauthorize:
+		if call != "214509008 "+GetAuthURL(CLIENT_ID, AuthURLMLB, "http://www.example.com") {
+			log.Printf("Error: unexpected reauthorization %s\n", call)
+			t.FailNow()
+		}
+	case <-time.After(time.Second):
+		log.Printf("Error: OnReauthorizationRequired should have been called\n")
+		t.FailNow()
+	}
+
+	//A new authorization of the user makes the client usable again
+	client.setAuthorization(Authorization{AccessToken: "valid token", ExpiresIn: time.Hour, ExpiresAt: time.Now().Add(time.Hour)})
+
+	if _, err := client.Get("/sites"); err != nil || client.NeedsReauthorization() || len(reauthorize) != 0 {
+		log.Printf("Error: the client should be usable after being authorized again. error: %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_Reauthorization_Is_Requested_With_The_Resolved_User_Id(t *testing.T) {
+
+	reauthorize := make(chan int64, 1)
+
+	config := MeliConfig{
+		ClientID: CLIENT_ID,
+		Secret:   CLIENT_SECRET,
+		HTTPClient: HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(strings.NewReader(`{"error":"invalid_grant"}`))}, nil
+		}),
+		OnReauthorizationRequired: func(userID int64, authURL string) {
+			reauthorize <- userID
+		},
+	}
+
+	//The token does not carry the id of the user, which was resolved by UserID
+	client, _ := NewClientFromToken(config, Authorization{AccessToken: "expired token", RefreshToken: "revoked refresh token"})
+	client.cacheUserID(214509008)
+
+	if _, err := client.Get("/sites"); !errors.Is(err, ErrReauthorizationRequired) {
+		log.Printf("Error: ErrReauthorizationRequired was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	select {
+	case userID := <-reauthorize:
+		if userID != 214509008 {
+			log.Printf("Error: the resolved user id should have been given, obtained %d\n", userID)
+			t.FailNow()
+		}
+	case <-time.After(time.Second):
+		log.Printf("Error: OnReauthorizationRequired should have been called\n")
+		t.FailNow()
+	}
+}
+
 func Test_GET_public_API_sites_works_properly(t *testing.T) {
 
 	client, err := newTestAnonymousClient(API_TEST)
@@ -350,6 +542,238 @@ func Test_DELETE_an_item_returns_200_when_token_IS_EXPIRED(t *testing.T) {
 	}
 }
 
+func Test_GET_returns_an_error_when_context_is_cancelled(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &Client{apiURL: server.URL, auth: anonymous, httpClient: MeliHTTPClient{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.GetContext(ctx, "/sites")
+
+	if !errors.Is(err, context.Canceled) {
+		log.Printf("Error: context.Canceled was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_MeliTokenRefresher_honors_the_context_deadline(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := &Client{id: CLIENT_ID, secret: CLIENT_SECRET, apiURL: server.URL, httpClient: MeliHTTPClient{}}
+	client.auth.RefreshToken = "valid refresh token"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := MeliTokenRefresher{}.RefreshToken(ctx, client)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Error: context.DeadlineExceeded was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_Do_sends_custom_method_headers_and_body_through_the_configured_http_client(t *testing.T) {
+
+	var method, header, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, header, body = r.Method, r.Header.Get("X-Custom"), string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &Client{apiURL: server.URL, auth: anonymous, httpClient: MeliHTTPClient{Client: server.Client()}}
+
+	req, _ := http.NewRequest(http.MethodPatch, "/items/123", strings.NewReader("{\"foo\":\"bar\"}"))
+	req.Header.Set("X-Custom", "custom")
+
+	resp, err := client.Do(req)
+
+	if err != nil || resp.StatusCode != http.StatusOK {
+		log.Printf("Error while patching an item %v\n", err)
+		t.FailNow()
+	}
+
+	if method != http.MethodPatch || header != "custom" || body != "{\"foo\":\"bar\"}" {
+		log.Printf("Error: unexpected request received: %s %s %s\n", method, header, body)
+		t.FailNow()
+	}
+}
+
+func Test_AdaptHTTPClient_returns_an_error_for_methods_not_supported_by_the_legacy_interface(t *testing.T) {
+
+	client := &Client{apiURL: API_TEST, auth: anonymous, httpClient: AdaptHTTPClient(MockHttpClientPostFailure{})}
+
+	_, err := client.Patch("/items/123", "{\"foo\":\"bar\"}")
+
+	if err == nil {
+		log.Printf("Error: An error should have been received.")
+		t.FailNow()
+	}
+}
+
+/*MockLegacyHttpClient implements the HTTPClient interface of the released versions, without contexts.*/
+type MockLegacyHttpClient struct {
+	calls *[]string
+}
+
+func (mock MockLegacyHttpClient) Get(url string) (*http.Response, error) {
+	return mock.respond("GET", url, "")
+}
+
+func (mock MockLegacyHttpClient) Post(url string, bodyType string, body io.Reader) (*http.Response, error) {
+	return mock.respond("POST", url, bodyType)
+}
+
+func (mock MockLegacyHttpClient) Put(url string, body io.Reader) (*http.Response, error) {
+	return mock.respond("PUT", url, "")
+}
+
+func (mock MockLegacyHttpClient) Delete(url string, body io.Reader) (*http.Response, error) {
+	return mock.respond("DELETE", url, "")
+}
+
+func (mock MockLegacyHttpClient) respond(method string, url string, bodyType string) (*http.Response, error) {
+	*mock.calls = append(*mock.calls, strings.TrimSpace(method+" "+url+" "+bodyType))
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func Test_AdaptLegacyHTTPClient_sends_the_requests_through_the_released_interface(t *testing.T) {
+
+	var calls []string
+	client := &Client{apiURL: API_TEST, auth: anonymous, httpClient: AdaptLegacyHTTPClient(MockLegacyHttpClient{calls: &calls})}
+
+	client.Get("/sites")
+	client.Post("/items", "{}")
+
+	if len(calls) != 2 || calls[0] != "GET "+API_TEST+"/sites" || calls[1] != "POST "+API_TEST+"/items application/json" {
+		log.Printf("Error: unexpected calls %v\n", calls)
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GetContext(ctx, "/sites"); !errors.Is(err, context.Canceled) || len(calls) != 2 {
+		log.Printf("Error: a cancelled call should not be sent, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_AdaptLegacyHTTPClient_sends_the_access_token_of_an_authorized_client(t *testing.T) {
+
+	var calls []string
+	client := &Client{apiURL: API_TEST, httpClient: AdaptLegacyHTTPClient(MockLegacyHttpClient{calls: &calls})}
+	client.auth = Authorization{AccessToken: "valid token", ExpiresIn: 10800 * time.Second, ReceivedAt: time.Now().Unix()}
+
+	if _, err := client.Get("/users/me?attributes=id"); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if len(calls) != 1 || calls[0] != "GET "+API_TEST+"/users/me?access_token=valid+token&attributes=id" {
+		log.Printf("Error: the access token should have been sent as a parameter, obtained %v\n", calls)
+		t.FailNow()
+	}
+
+	//Headers the released interface can not send are not dropped silently
+	ctx := WithIdempotencyKey(context.Background(), "a key")
+
+	if _, err := client.PostContext(ctx, "/items", "{}"); err == nil || len(calls) != 1 {
+		log.Printf("Error: a request with an idempotency key should have been rejected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_access_token_is_sent_in_the_Authorization_header(t *testing.T) {
+
+	var authorization, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, query = r.Header.Get("Authorization"), r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &Client{apiURL: server.URL, httpClient: MeliHTTPClient{Client: server.Client()}}
+	client.auth = Authorization{AccessToken: "valid token", ExpiresIn: 10800 * time.Second, ReceivedAt: time.Now().Unix()}
+
+	if _, err := client.Get("/users/me"); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if authorization != "Bearer valid token" || query != "" {
+		log.Printf("Error: unexpected credentials sent. header: %s query: %s\n", authorization, query)
+		t.FailNow()
+	}
+}
+
+func Test_credentials_are_redacted_from_urls(t *testing.T) {
+
+	redacted := redactURL(APIURL + "/users/me?access_token=APP_USR-123&attributes=id")
+
+	if strings.Contains(redacted, "APP_USR-123") || !strings.Contains(redacted, "attributes=id") {
+		log.Printf("Error: url was not properly redacted: %s\n", redacted)
+		t.FailNow()
+	}
+
+	err := redactError(&url.Error{Op: "Get", URL: APIURL + "/items?access_token=APP_USR-123", Err: errors.New("connection reset")})
+
+	if strings.Contains(err.Error(), "APP_USR-123") {
+		log.Printf("Error: error was not properly redacted: %s\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_credentials_are_not_written_to_the_debug_log(t *testing.T) {
+
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	debugEnable = true
+
+	defer func() {
+		debugEnable = false
+		log.SetOutput(os.Stderr)
+	}()
+
+	config := MeliConfig{
+		ClientID:     CLIENT_ID,
+		UserCode:     "TG-SECRET_CODE",
+		Secret:       CLIENT_SECRET,
+		DisableCache: true,
+		HTTPClient: HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			body := `{"access_token":"APP_USR-SECRET_TOKEN","refresh_token":"TG-SECRET_REFRESH","expires_in":"not a number"}`
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		}),
+	}
+
+	if _, err := MeliClient(config); err == nil {
+		log.Printf("Error: the malformed authorization should have been rejected\n")
+		t.FailNow()
+	}
+
+	for _, secret := range []string{"SECRET_CODE", "SECRET_TOKEN", "SECRET_REFRESH"} {
+		if strings.Contains(output.String(), secret) {
+			debugEnable = false
+			log.SetOutput(os.Stderr)
+			log.Printf("Error: %s was written to the debug log: %s\n", secret, output.String())
+			t.FailNow()
+		}
+	}
+}
+
 func Test_AuthorizationURL_adds_a_params_separator_when_needed(t *testing.T) {
 
 	auth := newAuthorizationURL(APIURL + "/authorizationauth")
@@ -379,45 +803,145 @@ func Test_AuthorizationURL_adds_a_query_param_separator_when_needed(t *testing.T
 
 func Test_only_one_token_refresh_call_is_done_when_several_threads_are_executed(t *testing.T) {
 
+	var clients []*Client
+	var refreshers []*MockCountingTokenRefresher
+
+	for i := 0; i < 5; i++ {
+		client, err := newTestClient(CLIENT_ID, USER_CODE, CLIENT_SECRET, "https://www.example.com", API_TEST)
+
+		if err != nil {
+			log.Printf("Error during Client instantation %s\n", err)
+			t.FailNow()
+		}
+
+		refresher := &MockCountingTokenRefresher{}
+		client.tokenRefresher = refresher
+		client.clock = &MockClock{now: time.Now().Add(4 * time.Hour)}
+
+		clients = append(clients, client)
+		refreshers = append(refreshers, refresher)
+	}
+
+	var errs int32
+	var wg sync.WaitGroup
+
+	for _, client := range clients {
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(client *Client) {
+				defer wg.Done()
+				if _, err := client.Get("/users/me"); err != nil {
+					atomic.AddInt32(&errs, 1)
+				}
+			}(client)
+		}
+	}
+	wg.Wait()
+
+	for i, refresher := range refreshers {
+		if calls := atomic.LoadInt32(&refresher.calls); calls != 1 {
+			log.Printf("Error: client %d refreshed its token %d times\n", i, calls)
+			t.FailNow()
+		}
+	}
+
+	if errs > 0 {
+		log.Printf("Error: %d calls failed\n", errs)
+		t.FailNow()
+	}
+}
+
+func Test_a_failed_refresh_is_returned_to_every_waiting_goroutine_and_does_not_block_later_calls(t *testing.T) {
+
 	client, err := newTestClient(CLIENT_ID, USER_CODE, CLIENT_SECRET, "https://www.example.com", API_TEST)
 
 	if err != nil {
 		log.Printf("Error during Client instantation %s\n", err)
 		t.FailNow()
 	}
-	client.auth.ExpiresIn = 0
 
-	wg.Add(100)
+	refreshErr := errors.New("refresh failed")
+	refresher := &MockCountingTokenRefresher{release: make(chan struct{}), err: refreshErr}
+	client.tokenRefresher = refresher
+	client.clock = &MockClock{now: time.Now().Add(4 * time.Hour)}
+
+	var started, finished sync.WaitGroup
+	var failed int32
+
 	for i := 0; i < 100; i++ {
-		go callHttpMethod(client)
+		started.Add(1)
+		finished.Add(1)
+		go func() {
+			defer finished.Done()
+			started.Done()
+			if _, err := client.Get("/users/me"); errors.Is(err, refreshErr) {
+				atomic.AddInt32(&failed, 1)
+			}
+		}()
 	}
-	wg.Wait()
 
-	if counter > 1 {
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(refresher.release)
+	finished.Wait()
+
+	if calls := atomic.LoadInt32(&refresher.calls); calls != 1 || failed != 100 {
+		log.Printf("Error: %d refresh calls and %d failed calls\n", calls, failed)
 		t.FailNow()
 	}
-}
 
-var counter = 0
-var m = sync.Mutex{}
+	client.tokenRefresher = MeliTokenRefresher{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := client.GetContext(ctx, "/users/me"); err != nil {
+		log.Printf("Error: the client should work once refreshing works again %s\n", err)
+		t.FailNow()
+	}
+}
 
 type MockTockenRefresher struct{}
 
-func (mock MockTockenRefresher) RefreshToken(client *Client) error {
+func (mock MockTockenRefresher) RefreshToken(ctx context.Context, client *Client) error {
 	realRefresher := MeliTokenRefresher{}
-	realRefresher.RefreshToken(client)
-	m.Lock()
-	counter++
-	fmt.Printf("counter %d", counter)
-	m.Unlock()
-	return nil
+	return realRefresher.RefreshToken(ctx, client)
+}
+
+/*MockClock is a Clock which only moves when told to.*/
+type MockClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (clock *MockClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+func (clock *MockClock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = clock.now.Add(d)
 }
 
-var wg sync.WaitGroup
+/*MockCountingTokenRefresher counts the refresh calls. When release is set, it waits for it to be closed and returns err if any.*/
+type MockCountingTokenRefresher struct {
+	calls   int32
+	release chan struct{}
+	err     error
+}
 
-func callHttpMethod(client *Client) {
-	defer wg.Done()
-	client.Get("/users/me")
+func (mock *MockCountingTokenRefresher) RefreshToken(ctx context.Context, client *Client) error {
+	atomic.AddInt32(&mock.calls, 1)
+	if mock.release != nil {
+		<-mock.release
+	}
+	if mock.err != nil {
+		return mock.err
+	}
+	return MeliTokenRefresher{}.RefreshToken(ctx, client)
 }
 
 /*
@@ -434,7 +958,7 @@ func newTestClient(id int64, code string, secret string, redirectUrl string, api
 
 	client := &Client{id: id, code: code, secret: secret, redirectURL: redirectUrl, apiURL: apiUrl, httpClient: MockHttpClient{}, tokenRefresher: MockTockenRefresher{}}
 
-	auth, err := client.authorize()
+	auth, err := client.authorize(context.Background())
 
 	if err != nil {
 		return nil, err
@@ -448,7 +972,26 @@ func newTestClient(id int64, code string, secret string, redirectUrl string, api
 type MockHttpClient struct {
 }
 
-func (httpClient MockHttpClient) Get(url string) (*http.Response, error) {
+func (httpClient MockHttpClient) Do(req *http.Request) (*http.Response, error) {
+
+	uri := req.URL.String()
+	accessToken := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+
+	switch req.Method {
+	case http.MethodGet:
+		return httpClient.get(uri)
+	case http.MethodPost:
+		return httpClient.post(uri, accessToken, req.Body)
+	case http.MethodPut:
+		return httpClient.put(uri, accessToken, req.Body)
+	case http.MethodDelete:
+		return httpClient.delete(uri, accessToken)
+	}
+
+	return nil, errors.New("method not supported by MockHttpClient")
+}
+
+func (httpClient MockHttpClient) get(url string) (*http.Response, error) {
 
 	resp := new(http.Response)
 
@@ -465,17 +1008,24 @@ func (httpClient MockHttpClient) Get(url string) (*http.Response, error) {
 	return resp, nil
 }
 
-func (httpClient MockHttpClient) Post(uri string, bodyType string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClient) post(uri string, access_token string, body io.Reader) (*http.Response, error) {
 
 	resp := new(http.Response)
-	fullUri, _ := url.Parse(uri)
 
 	if strings.Contains(uri, "/oauth/token") {
 
-		grant_type := fullUri.Query().Get("grant_type")
+		b, _ := ioutil.ReadAll(body)
+		form, _ := url.ParseQuery(string(b))
+
+		if strings.Contains(uri, "?") {
+			resp.StatusCode = http.StatusBadRequest
+			return resp, nil
+		}
+
+		grant_type := form.Get("grant_type")
 
 		if strings.Compare(grant_type, "authorization_code") == 0 {
-			code := fullUri.Query().Get("code")
+			code := form.Get("code")
 
 			if strings.Compare(code, "bad code") == 0 {
 
@@ -494,20 +1044,22 @@ func (httpClient MockHttpClient) Post(uri string, bodyType string, body io.Reade
 
 			} else if strings.Compare(code, "valid code with refresh token") == 0 ||
 				strings.Compare(code, "ANOTHER_CODE") == 0 ||
-				strings.Compare(code, "AUTHORIZED_CLIENT") == 0 {
+				strings.Compare(code, "AUTHORIZED_CLIENT") == 0 ||
+				strings.Compare(code, "STORED_CLIENT") == 0 {
 
 				resp.Body = ioutil.NopCloser(bytes.NewReader([]byte(
 					"{\"access_token\":\"valid token\"," +
 						"\"token_type\":\"bearer\"," +
 						"\"expires_in\":10800," +
 						"\"refresh_token\":\"valid refresh token\"," +
-						"\"scope\":\"write read\"}")))
+						"\"scope\":\"write read\"," +
+						"\"user_id\":" + TEST_USER_ID + "}")))
 
 			}
 
 		} else if strings.Compare(grant_type, "refresh_token") == 0 {
 
-			refresh := fullUri.Query().Get("refresh_token")
+			refresh := form.Get("refresh_token")
 
 			if strings.Compare(refresh, "valid refresh token") == 0 {
 
@@ -519,12 +1071,12 @@ func (httpClient MockHttpClient) Post(uri string, bodyType string, body io.Reade
 			}
 		}
 
-		resp.StatusCode = http.StatusOK
+		if resp.StatusCode == 0 {
+			resp.StatusCode = http.StatusOK
+		}
 
 	} else if strings.Contains(uri, "/items") {
 
-		access_token := fullUri.Query().Get("access_token")
-
 		if strings.Compare(access_token, "valid token") == 0 {
 
 			b, _ := ioutil.ReadAll(body)
@@ -539,15 +1091,12 @@ func (httpClient MockHttpClient) Post(uri string, bodyType string, body io.Reade
 	return resp, nil
 }
 
-func (httpClient MockHttpClient) Put(uri string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClient) put(uri string, access_token string, body io.Reader) (*http.Response, error) {
 
 	resp := new(http.Response)
-	fullUri, _ := url.Parse(uri)
 
 	if strings.Contains(uri, "/items/123") {
 
-		access_token := fullUri.Query().Get("access_token")
-
 		if strings.Compare(access_token, "valid token") == 0 {
 
 			b, _ := ioutil.ReadAll(body)
@@ -567,13 +1116,11 @@ func (httpClient MockHttpClient) Put(uri string, body io.Reader) (*http.Response
 	return resp, nil
 }
 
-func (httpClient MockHttpClient) Delete(uri string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClient) delete(uri string, access_token string) (*http.Response, error) {
 
 	resp := new(http.Response)
-	fullUri, _ := url.Parse(uri)
 
 	if strings.Contains(uri, "/items/123") {
-		access_token := fullUri.Query().Get("access_token")
 
 		if strings.Compare(access_token, "valid token") == 0 {
 			resp.StatusCode = http.StatusOK
@@ -590,38 +1137,38 @@ func (httpClient MockHttpClient) Delete(uri string, body io.Reader) (*http.Respo
 type MockHttpClientPostFailure struct {
 }
 
-func (httpClient MockHttpClientPostFailure) Post(uri string, bodyType string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClientPostFailure) Post(ctx context.Context, uri string, bodyType string, body io.Reader) (*http.Response, error) {
 	return nil, errors.New("Error")
 }
-func (httpClient MockHttpClientPostFailure) Get(url string) (*http.Response, error) {
+func (httpClient MockHttpClientPostFailure) Get(ctx context.Context, url string) (*http.Response, error) {
 	return nil, nil
 }
 
-func (httpClient MockHttpClientPostFailure) Delete(uri string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClientPostFailure) Delete(ctx context.Context, uri string, body io.Reader) (*http.Response, error) {
 	return nil, nil
 }
 
-func (httpClient MockHttpClientPostFailure) Put(uri string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClientPostFailure) Put(ctx context.Context, uri string, body io.Reader) (*http.Response, error) {
 	return nil, nil
 }
 
 type MockHttpClientPostNonOKStatusCode struct {
 }
 
-func (httpClient MockHttpClientPostNonOKStatusCode) Post(uri string, bodyType string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClientPostNonOKStatusCode) Post(ctx context.Context, uri string, bodyType string, body io.Reader) (*http.Response, error) {
 
 	httpResponse := http.Response{}
 	httpResponse.StatusCode = http.StatusForbidden
 	return new(http.Response), nil
 }
-func (httpClient MockHttpClientPostNonOKStatusCode) Get(url string) (*http.Response, error) {
+func (httpClient MockHttpClientPostNonOKStatusCode) Get(ctx context.Context, url string) (*http.Response, error) {
 	return nil, nil
 }
 
-func (httpClient MockHttpClientPostNonOKStatusCode) Delete(uri string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClientPostNonOKStatusCode) Delete(ctx context.Context, uri string, body io.Reader) (*http.Response, error) {
 	return nil, nil
 }
 
-func (httpClient MockHttpClientPostNonOKStatusCode) Put(uri string, body io.Reader) (*http.Response, error) {
+func (httpClient MockHttpClientPostNonOKStatusCode) Put(ctx context.Context, uri string, body io.Reader) (*http.Response, error) {
 	return nil, nil
 }
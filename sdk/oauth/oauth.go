@@ -0,0 +1,257 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package oauth provides the http.Handlers which take a user through the mercadolibre authorization flow:
+the login handler redirects the user to mercadolibre, and the callback handler exchanges the code it receives
+for an authorized sdk.Client.
+*/
+package oauth
+
+import (
+	"container/list"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mercadolibre/golang-sdk/sdk"
+)
+
+/*ErrAccessDenied is given to OnError when the user did not authorize the application.*/
+var ErrAccessDenied = errors.New("the user did not authorize the application")
+
+/*ErrUnknownState is given to OnError when the callback is not part of an authorization started by the login handler.*/
+var ErrUnknownState = errors.New("the authorization was not started by this application or it expired")
+
+const (
+	stateCookie       = "meli_oauth_state"
+	defaultStateTTL   = 10 * time.Minute
+	defaultMaxPending = 10000
+)
+
+/*User is the mercadolibre user who authorized the application, as returned by /users/me.*/
+type User = sdk.User
+
+/*
+Flow holds the state of the authorizations in progress, and provides the login and callback handlers.
+Config has to carry at least the ClientID, the Secret and the CallBackURL, which must point to the callback handler.
+Set Config.TokenStore to keep the tokens obtained, so the clients can be built again later by their user id.
+
+The state and the PKCE code_verifier of each authorization are kept in memory, and the state is bound to the browser
+by a cookie. The flows started by a process have to be completed by the same one. Up to MaxPending authorizations are
+kept, and the oldest one is forgotten when a new one is started beyond that.
+*/
+type Flow struct {
+	Config     sdk.MeliConfig
+	BaseSite   string        // Site id or base URL of its authorization page, i.e. MLA or sdk.AuthURLMLA
+	StateTTL   time.Duration // Time the user has to authorize the application. 10 minutes when zero
+	MaxPending int           // Max number of authorizations in progress. 10000 when zero
+
+	// OnAuthorized is called with the client of the user, once the code was exchanged. It has to write the response.
+	// The user is redirected to / when it is nil. It is called even if Config.TokenStore failed to save the token,
+	// which can be saved again from client.Authorization().
+	OnAuthorized func(w http.ResponseWriter, r *http.Request, client *sdk.Client, user User)
+
+	// OnError is called when the authorization fails. It has to write the response.
+	// A plain error page is written when it is nil.
+	OnError func(w http.ResponseWriter, r *http.Request, err error)
+
+	mutex   sync.Mutex
+	pending map[string]*list.Element // By state
+	order   list.List                // Of *pendingRequest, the oldest first
+}
+
+type pendingRequest struct {
+	request   sdk.AuthRequest
+	expiresAt time.Time
+}
+
+/*New returns a Flow which calls onAuthorized once a user authorized the application.*/
+func New(config sdk.MeliConfig, baseSite string, onAuthorized func(w http.ResponseWriter, r *http.Request, client *sdk.Client, user User)) *Flow {
+	return &Flow{Config: config, BaseSite: baseSite, OnAuthorized: onAuthorized}
+}
+
+/*LoginHandler returns the handler which redirects the user to the mercadolibre authorization page.*/
+func (flow *Flow) LoginHandler() http.Handler {
+	return http.HandlerFunc(flow.login)
+}
+
+/*CallbackHandler returns the handler mercadolibre redirects the user to. It has to be served at Config.CallBackURL.*/
+func (flow *Flow) CallbackHandler() http.Handler {
+	return http.HandlerFunc(flow.callback)
+}
+
+func (flow *Flow) login(w http.ResponseWriter, r *http.Request) {
+
+	builder := sdk.AuthURLBuilder{ClientID: flow.Config.ClientID, BaseSite: flow.BaseSite, CallBackURL: flow.Config.CallBackURL}
+
+	request, err := builder.Build()
+	if err != nil {
+		flow.fail(w, r, err)
+		return
+	}
+
+	flow.addRequest(*request)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    request.State,
+		Path:     "/",
+		MaxAge:   int(flow.stateTTL().Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, request.URL, http.StatusFound)
+}
+
+func (flow *Flow) callback(w http.ResponseWriter, r *http.Request) {
+
+	//The state can only be used once
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/", MaxAge: -1, HttpOnly: true, Secure: r.TLS != nil})
+
+	query := r.URL.Query()
+
+	if query.Get("error") != "" {
+		flow.fail(w, r, ErrAccessDenied)
+		return
+	}
+
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil {
+		flow.fail(w, r, ErrUnknownState)
+		return
+	}
+
+	request, ok := flow.takeRequest(cookie.Value)
+	if !ok {
+		flow.fail(w, r, ErrUnknownState)
+		return
+	}
+
+	//A token which could not be saved is usable nonetheless, and the code can not be exchanged again
+	client, err := sdk.ExchangeCode(r.Context(), flow.Config, request, query.Get("state"), query.Get("code"))
+	if err != nil && !errors.Is(err, sdk.ErrTokenNotSaved) {
+		flow.fail(w, r, err)
+		return
+	}
+
+	user, err := client.Users().Me(r.Context())
+	if err != nil {
+		flow.fail(w, r, err)
+		return
+	}
+
+	if flow.OnAuthorized == nil {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	flow.OnAuthorized(w, r, client, *user)
+}
+
+/*addRequest remembers an authorization in progress, forgetting the oldest ones beyond MaxPending.*/
+func (flow *Flow) addRequest(request sdk.AuthRequest) {
+
+	flow.mutex.Lock()
+	defer flow.mutex.Unlock()
+
+	now := time.Now()
+	flow.evictExpired(now)
+
+	if flow.pending == nil {
+		flow.pending = make(map[string]*list.Element)
+	}
+
+	for flow.order.Len() >= flow.maxPending() {
+		flow.remove(flow.order.Front())
+	}
+
+	flow.pending[request.State] = flow.order.PushBack(&pendingRequest{request: request, expiresAt: now.Add(flow.stateTTL())})
+}
+
+/*takeRequest returns the authorization started with the given state and forgets it.*/
+func (flow *Flow) takeRequest(state string) (sdk.AuthRequest, bool) {
+
+	flow.mutex.Lock()
+	defer flow.mutex.Unlock()
+
+	flow.evictExpired(time.Now())
+
+	element := flow.pending[state]
+	if element == nil {
+		return sdk.AuthRequest{}, false
+	}
+
+	flow.remove(element)
+
+	return element.Value.(*pendingRequest).request, true
+}
+
+/*
+evictExpired forgets the authorizations the users did not complete in time. Since they all last StateTTL, the oldest
+ones expire first. The mutex must be held.
+*/
+func (flow *Flow) evictExpired(now time.Time) {
+
+	for element := flow.order.Front(); element != nil && now.After(element.Value.(*pendingRequest).expiresAt); element = flow.order.Front() {
+		flow.remove(element)
+	}
+}
+
+/*remove forgets a pending authorization. The mutex must be held.*/
+func (flow *Flow) remove(element *list.Element) {
+
+	flow.order.Remove(element)
+	delete(flow.pending, element.Value.(*pendingRequest).request.State)
+}
+
+func (flow *Flow) maxPending() int {
+
+	if flow.MaxPending <= 0 {
+		return defaultMaxPending
+	}
+
+	return flow.MaxPending
+}
+
+func (flow *Flow) stateTTL() time.Duration {
+
+	if flow.StateTTL <= 0 {
+		return defaultStateTTL
+	}
+
+	return flow.StateTTL
+}
+
+func (flow *Flow) fail(w http.ResponseWriter, r *http.Request, err error) {
+
+	if flow.OnError != nil {
+		flow.OnError(w, r, err)
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrUnknownState), errors.Is(err, sdk.ErrStateMismatch), sdk.IsInvalidGrant(err):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "the authorization could not be completed", http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mercadolibre/golang-sdk/sdk"
+)
+
+const testUserID = 214509008
+
+/*mockAPI answers the token exchange, checking the code_verifier is sent, and /users/me.*/
+func mockAPI(req *http.Request) (*http.Response, error) {
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+
+	switch req.URL.Path {
+	case "/oauth/token":
+		body, _ := ioutil.ReadAll(req.Body)
+		form, _ := url.ParseQuery(string(body))
+		if form.Get("code") != "valid code" || form.Get("code_verifier") == "" {
+			resp.StatusCode = http.StatusBadRequest
+			resp.Body = ioutil.NopCloser(bytes.NewBufferString(`{"error":"invalid_grant"}`))
+			return resp, nil
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewBufferString(
+			`{"access_token":"valid token","token_type":"bearer","expires_in":10800,"refresh_token":"valid refresh token","user_id":214509008}`))
+	case "/users/me":
+		resp.Body = ioutil.NopCloser(bytes.NewBufferString(`{"id":214509008,"nickname":"TEST_USER","site_id":"MLA"}`))
+	default:
+		resp.StatusCode = http.StatusNotFound
+		resp.Body = ioutil.NopCloser(bytes.NewBufferString(`{}`))
+	}
+
+	return resp, nil
+}
+
+func newTestFlow(store sdk.TokenStore, authorized *User) *Flow {
+
+	config := sdk.MeliConfig{
+		ClientID:     123456,
+		Secret:       "client secret",
+		CallBackURL:  "http://www.example.com/callback",
+		HTTPClient:   sdk.HTTPClientFunc(mockAPI),
+		TokenStore:   store,
+		DisableCache: true,
+	}
+
+	return New(config, sdk.AuthURLMLA, func(w http.ResponseWriter, r *http.Request, client *sdk.Client, user User) {
+		*authorized = user
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+/*login performs a request to the login handler and returns the state sent to mercadolibre along with its cookie.*/
+func login(flow *Flow) (string, *http.Cookie) {
+
+	recorder := httptest.NewRecorder()
+	flow.LoginHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+	location, _ := url.Parse(recorder.Header().Get("Location"))
+	cookies := recorder.Result().Cookies()
+
+	if len(cookies) == 0 {
+		return location.Query().Get("state"), nil
+	}
+
+	return location.Query().Get("state"), cookies[0]
+}
+
+func callback(flow *Flow, state string, code string, cookie *http.Cookie) *httptest.ResponseRecorder {
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?"+url.Values{"state": {state}, "code": {code}}.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	flow.CallbackHandler().ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func Test_login_redirects_to_the_authorization_page_with_state_and_code_challenge(t *testing.T) {
+
+	var user User
+	flow := newTestFlow(nil, &user)
+
+	recorder := httptest.NewRecorder()
+	flow.LoginHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+	location, err := url.Parse(recorder.Header().Get("Location"))
+
+	if err != nil || recorder.Code != http.StatusFound || location.Host != "auth.mercadolibre.com.ar" ||
+		location.Query().Get("state") == "" || location.Query().Get("code_challenge") == "" {
+		log.Printf("Error: unexpected redirection %d %s\n", recorder.Code, recorder.Header().Get("Location"))
+		t.FailNow()
+	}
+
+	cookies := recorder.Result().Cookies()
+
+	if len(cookies) != 1 || cookies[0].Value != location.Query().Get("state") || !cookies[0].HttpOnly {
+		log.Printf("Error: the state should have been bound to the browser by a cookie\n")
+		t.FailNow()
+	}
+}
+
+func Test_callback_exchanges_the_code_stores_the_token_and_calls_OnAuthorized(t *testing.T) {
+
+	var user User
+	store := sdk.NewMemoryTokenStore()
+	flow := newTestFlow(store, &user)
+
+	state, cookie := login(flow)
+	recorder := callback(flow, state, "valid code", cookie)
+
+	if recorder.Code != http.StatusNoContent || user.ID != testUserID || user.Nickname != "TEST_USER" {
+		log.Printf("Error: OnAuthorized should have been called with the user. status: %d body: %s\n", recorder.Code, recorder.Body)
+		t.FailNow()
+	}
+
+	if auth, err := store.Load(context.Background(), 123456, testUserID); err != nil || auth.RefreshToken != "valid refresh token" {
+		log.Printf("Error: the token should have been stored %v\n", err)
+		t.FailNow()
+	}
+
+	//The state can not be used twice
+	if recorder := callback(flow, state, "valid code", cookie); recorder.Code != http.StatusBadRequest {
+		log.Printf("Error: a replayed callback should have been rejected. status: %d\n", recorder.Code)
+		t.FailNow()
+	}
+}
+
+func Test_callback_rejects_a_state_which_does_not_match_the_cookie(t *testing.T) {
+
+	var user User
+	flow := newTestFlow(nil, &user)
+
+	var failure error
+	flow.OnError = func(w http.ResponseWriter, r *http.Request, err error) {
+		failure = err
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+
+	state, cookie := login(flow)
+
+	if callback(flow, state, "valid code", nil); !errors.Is(failure, ErrUnknownState) {
+		log.Printf("Error: a callback without cookie should have been rejected, obtained %v\n", failure)
+		t.FailNow()
+	}
+
+	state, cookie = login(flow)
+
+	if callback(flow, "forged "+state, "valid code", cookie); !errors.Is(failure, sdk.ErrStateMismatch) {
+		log.Printf("Error: a forged state should have been rejected, obtained %v\n", failure)
+		t.FailNow()
+	}
+
+	if user.ID != 0 {
+		log.Printf("Error: OnAuthorized should not have been called\n")
+		t.FailNow()
+	}
+}
+
+func Test_callback_reports_when_the_user_denies_the_authorization(t *testing.T) {
+
+	var user User
+	flow := newTestFlow(nil, &user)
+
+	_, cookie := login(flow)
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?error=access_denied", nil)
+	req.AddCookie(cookie)
+
+	recorder := httptest.NewRecorder()
+	flow.CallbackHandler().ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusForbidden {
+		log.Printf("Error: 403 was expected, obtained %d\n", recorder.Code)
+		t.FailNow()
+	}
+}
+
+func Test_the_oldest_authorizations_are_forgotten_beyond_MaxPending(t *testing.T) {
+
+	var user User
+	flow := newTestFlow(nil, &user)
+	flow.MaxPending = 2
+
+	oldestState, oldestCookie := login(flow)
+	login(flow)
+	state, cookie := login(flow)
+
+	if n := len(flow.pending); n != 2 || flow.order.Len() != 2 {
+		log.Printf("Error: up to 2 authorizations should have been kept, obtained %d\n", n)
+		t.FailNow()
+	}
+
+	if recorder := callback(flow, oldestState, "valid code", oldestCookie); recorder.Code != http.StatusBadRequest {
+		log.Printf("Error: the oldest authorization should have been forgotten. status: %d\n", recorder.Code)
+		t.FailNow()
+	}
+
+	if recorder := callback(flow, state, "valid code", cookie); recorder.Code != http.StatusNoContent || user.ID != testUserID {
+		log.Printf("Error: the newest authorization should have been completed. status: %d body: %s\n", recorder.Code, recorder.Body)
+		t.FailNow()
+	}
+}
+
+/*unavailableStore can not save any token.*/
+type unavailableStore struct {
+	*sdk.MemoryTokenStore
+}
+
+func (store unavailableStore) Save(ctx context.Context, clientID int64, userID int64, auth sdk.Authorization) error {
+	return errors.New("the store is not available")
+}
+
+func Test_callback_calls_OnAuthorized_when_the_token_can_not_be_saved(t *testing.T) {
+
+	var user User
+	flow := newTestFlow(unavailableStore{sdk.NewMemoryTokenStore()}, &user)
+
+	state, cookie := login(flow)
+
+	if recorder := callback(flow, state, "valid code", cookie); recorder.Code != http.StatusNoContent || user.ID != testUserID {
+		log.Printf("Error: OnAuthorized should have been called with the user. status: %d body: %s\n", recorder.Code, recorder.Body)
+		t.FailNow()
+	}
+}
+
+func Test_a_reauthorization_is_completed_through_the_login_handler(t *testing.T) {
+
+	var user User
+	flow := newTestFlow(nil, &user)
+
+	reauthorize := make(chan string, 1)
+
+	config := flow.Config
+	config.LoginURL = "http://www.example.com/login"
+	config.OnReauthorizationRequired = func(userID int64, authURL string) { reauthorize <- authURL }
+
+	//The refresh token was revoked by the user
+	client, _ := sdk.NewClientFromToken(config, sdk.Authorization{AccessToken: "expired token", RefreshToken: "revoked refresh token", UserID: testUserID})
+	if _, err := client.Get("/users/me"); !errors.Is(err, sdk.ErrReauthorizationRequired) {
+		log.Printf("Error: ErrReauthorizationRequired was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	var authURL string
+	select {
+	case authURL = <-reauthorize:
+	case <-time.After(time.Second):
+		log.Printf("Error: OnReauthorizationRequired should have been called\n")
+		t.FailNow()
+	}
+
+	recorder := httptest.NewRecorder()
+	flow.LoginHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, authURL, nil))
+
+	location, _ := url.Parse(recorder.Header().Get("Location"))
+	cookies := recorder.Result().Cookies()
+
+	if len(cookies) == 0 {
+		log.Printf("Error: the reauthorization URL should have started a new authorization\n")
+		t.FailNow()
+	}
+
+	if recorder := callback(flow, location.Query().Get("state"), "valid code", cookies[0]); recorder.Code != http.StatusNoContent || user.ID != testUserID {
+		log.Printf("Error: the reauthorization should have been completed. status: %d body: %s\n", recorder.Code, recorder.Body)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,345 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*Status of the orders.*/
+const (
+	OrderConfirmed        = "confirmed"
+	OrderPaymentRequired  = "payment_required"
+	OrderPaymentInProcess = "payment_in_process"
+	OrderPartiallyPaid    = "partially_paid"
+	OrderPaid             = "paid"
+	OrderCancelled        = "cancelled"
+	OrderInvalid          = "invalid"
+)
+
+/*Sort orders accepted by the order search.*/
+const (
+	OrderSortDateAsc  = "date_asc"
+	OrderSortDateDesc = "date_desc"
+)
+
+/*Format of the dates sent as order search filters.*/
+const orderDateFormat = "2006-01-02T15:04:05.000-07:00"
+
+/*Order is a purchase of one or more items of a seller.*/
+type Order struct {
+	ID           int64         `json:"id"`
+	Status       string        `json:"status"`
+	StatusDetail *StatusDetail `json:"status_detail"`
+	DateCreated  *time.Time    `json:"date_created"`
+	DateClosed   *time.Time    `json:"date_closed"`
+	LastUpdated  *time.Time    `json:"last_updated"`
+	TotalAmount  float64       `json:"total_amount"`
+	PaidAmount   float64       `json:"paid_amount"`
+	CurrencyID   string        `json:"currency_id"`
+	OrderItems   []OrderItem   `json:"order_items"`
+	Buyer        OrderUser     `json:"buyer"`
+	Seller       OrderUser     `json:"seller"`
+	Payments     []Payment     `json:"payments"`
+	Feedback     OrderFeedback `json:"feedback"`
+	Shipping     OrderShipping `json:"shipping"`
+	PackID       int64         `json:"pack_id"`
+	Tags         []string      `json:"tags"`
+}
+
+/*StatusDetail explains the status of an order, i.e. why it was cancelled.*/
+type StatusDetail struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+/*OrderItem is an item bought within an order.*/
+type OrderItem struct {
+	Item struct {
+		ID                  string      `json:"id"`
+		Title               string      `json:"title"`
+		CategoryID          string      `json:"category_id"`
+		VariationID         int64       `json:"variation_id"`
+		SellerSKU           string      `json:"seller_sku"`
+		VariationAttributes []Attribute `json:"variation_attributes"`
+	} `json:"item"`
+	Quantity      int     `json:"quantity"`
+	UnitPrice     float64 `json:"unit_price"`
+	FullUnitPrice float64 `json:"full_unit_price"`
+	CurrencyID    string  `json:"currency_id"`
+	SaleFee       float64 `json:"sale_fee"`
+}
+
+/*OrderUser is the buyer, or the seller, of an order.*/
+type OrderUser struct {
+	ID        int64  `json:"id"`
+	Nickname  string `json:"nickname"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+/*Payment is one of the payments of an order.*/
+type Payment struct {
+	ID                int64      `json:"id"`
+	OrderID           int64      `json:"order_id"`
+	PayerID           int64      `json:"payer_id"`
+	Status            string     `json:"status"`
+	StatusDetail      string     `json:"status_detail"`
+	PaymentType       string     `json:"payment_type"`
+	PaymentMethodID   string     `json:"payment_method_id"`
+	Installments      int        `json:"installments"`
+	TransactionAmount float64    `json:"transaction_amount"`
+	ShippingCost      float64    `json:"shipping_cost"`
+	TotalPaidAmount   float64    `json:"total_paid_amount"`
+	CurrencyID        string     `json:"currency_id"`
+	DateCreated       *time.Time `json:"date_created"`
+	DateApproved      *time.Time `json:"date_approved"`
+}
+
+/*OrderFeedback holds the feedback given by the seller (Sale) and by the buyer (Purchase), nil until given.*/
+type OrderFeedback struct {
+	Sale     *Feedback `json:"sale"`
+	Purchase *Feedback `json:"purchase"`
+}
+
+/*Feedback given by one of the parties of an order.*/
+type Feedback struct {
+	ID          int64      `json:"id"`
+	Rating      string     `json:"rating"`
+	Status      string     `json:"status"`
+	Fulfilled   bool       `json:"fulfilled"`
+	DateCreated *time.Time `json:"date_created"`
+}
+
+/*OrderShipping is the shipment of an order, which can be retrieved from the shipments API.*/
+type OrderShipping struct {
+	ID int64 `json:"id"`
+}
+
+/*
+OrderSearch filters the orders returned by Search and Iterate. Either Seller or Buyer is required by mercadolibre;
+when none is set, the orders sold by the user of the client are searched. Zero fields are not sent.
+*/
+type OrderSearch struct {
+	Seller      int64
+	Buyer       int64
+	Status      string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	Tags        []string
+	Sort        string // OrderSortDateAsc or OrderSortDateDesc
+	Offset      int
+	Limit       int  // Orders per page. 50 is the max accepted by mercadolibre
+	Scroll      bool // Walk the results by scroll ids instead of offsets, which is not limited to the first pages
+}
+
+/*OrderPage is a page of the results of an order search.*/
+type OrderPage struct {
+	Results  []Order `json:"results"`
+	Paging   Paging  `json:"paging"`
+	ScrollID string  `json:"scroll_id"`
+}
+
+/*OrdersService gives typed access to the /orders resources.*/
+type OrdersService struct {
+	client *Client
+}
+
+/*Orders returns the service to retrieve the orders of the user of the client.*/
+func (client *Client) Orders() *OrdersService {
+	return &OrdersService{client: client}
+}
+
+/*Get retrieves the order with the given id.*/
+func (service *OrdersService) Get(ctx context.Context, id int64) (*Order, error) {
+
+	order := new(Order)
+	if err := service.client.getJSON(ctx, "/orders/"+strconv.FormatInt(id, 10), order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+/*Search retrieves a single page of orders. See Iterate to walk every page.*/
+func (service *OrdersService) Search(ctx context.Context, search OrderSearch) (*OrderPage, error) {
+	return service.search(ctx, search, "")
+}
+
+func (service *OrdersService) search(ctx context.Context, search OrderSearch, scrollID string) (*OrderPage, error) {
+
+	query, err := service.query(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	if scrollID != "" {
+		query.Set("scroll_id", scrollID)
+	}
+
+	page := new(OrderPage)
+	if err := service.client.getJSON(ctx, "/orders/search?"+query.Encode(), page); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (service *OrdersService) query(ctx context.Context, search OrderSearch) (url.Values, error) {
+
+	query := url.Values{}
+
+	if search.Seller == 0 && search.Buyer == 0 {
+		seller, err := service.client.UserID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		search.Seller = seller
+	}
+
+	if search.Seller != 0 {
+		query.Set("seller", strconv.FormatInt(search.Seller, 10))
+	}
+	if search.Buyer != 0 {
+		query.Set("buyer", strconv.FormatInt(search.Buyer, 10))
+	}
+	if search.Status != "" {
+		query.Set("order.status", search.Status)
+	}
+
+	setDate := func(name string, date time.Time) {
+		if !date.IsZero() {
+			query.Set(name, date.Format(orderDateFormat))
+		}
+	}
+	setDate("order.date_created.from", search.CreatedFrom)
+	setDate("order.date_created.to", search.CreatedTo)
+	setDate("order.date_last_updated.from", search.UpdatedFrom)
+	setDate("order.date_last_updated.to", search.UpdatedTo)
+
+	if len(search.Tags) > 0 {
+		query.Set("tags", strings.Join(search.Tags, ","))
+	}
+	if search.Sort != "" {
+		query.Set("sort", search.Sort)
+	}
+	if search.Offset > 0 && !search.Scroll {
+		query.Set("offset", strconv.Itoa(search.Offset))
+	}
+	if search.Limit > 0 {
+		query.Set("limit", strconv.Itoa(search.Limit))
+	}
+	if search.Scroll {
+		query.Set("search_type", "scan")
+	}
+
+	return query, nil
+}
+
+/*
+Iterate walks every order matching search, requesting the following pages (by offset or by scroll id) as they are needed:
+
+	orders := client.Orders().Iterate(ctx, sdk.OrderSearch{Status: sdk.OrderPaid})
+	for orders.Next() {
+		order := orders.Order()
+	}
+	if err := orders.Err(); err != nil {
+		...
+	}
+*/
+func (service *OrdersService) Iterate(ctx context.Context, search OrderSearch) *OrderIterator {
+	return &OrderIterator{ctx: ctx, service: service, search: search}
+}
+
+/*OrderIterator walks the results of an order search. It must not be used by several goroutines at once.*/
+type OrderIterator struct {
+	ctx      context.Context
+	service  *OrdersService
+	search   OrderSearch
+	scrollID string
+	page     []Order
+	current  Order
+	started  bool
+	done     bool
+	err      error
+}
+
+/*Next advances to the next order, returning false once there are no more orders or a page could not be retrieved.*/
+func (iterator *OrderIterator) Next() bool {
+
+	if len(iterator.page) == 0 && !iterator.fetch() {
+		return false
+	}
+
+	iterator.current = iterator.page[0]
+	iterator.page = iterator.page[1:]
+
+	return true
+}
+
+/*Order returns the order Next advanced to.*/
+func (iterator *OrderIterator) Order() Order {
+	return iterator.current
+}
+
+/*Err returns the error which stopped the iteration, if any.*/
+func (iterator *OrderIterator) Err() error {
+	return iterator.err
+}
+
+func (iterator *OrderIterator) fetch() bool {
+
+	if iterator.done || iterator.err != nil {
+		return false
+	}
+
+	if iterator.started && iterator.search.Scroll && iterator.scrollID == "" {
+		iterator.done = true
+		return false
+	}
+
+	page, err := iterator.service.search(iterator.ctx, iterator.search, iterator.scrollID)
+	if err != nil {
+		iterator.err = err
+		return false
+	}
+
+	iterator.started = true
+	iterator.page = page.Results
+	iterator.scrollID = page.ScrollID
+
+	if len(page.Results) == 0 {
+		iterator.done = true
+		return false
+	}
+
+	if !iterator.search.Scroll {
+		iterator.search.Offset = page.Paging.Offset + len(page.Results)
+		if iterator.search.Offset >= page.Paging.Total {
+			iterator.done = true
+		}
+	}
+
+	return true
+}
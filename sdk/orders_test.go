@@ -0,0 +1,172 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Orders_Get_returns_a_typed_order(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders/2000003508" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id":2000003508,"status":"paid","total_amount":50,"currency_id":"ARS",
+			"order_items":[{"item":{"id":"MLA123","title":"Item de test"},"quantity":2,"unit_price":25}],
+			"buyer":{"id":123,"nickname":"TEST_BUYER"},"payments":[{"id":99,"status":"approved","transaction_amount":50}],
+			"feedback":{"sale":null,"purchase":{"rating":"positive","fulfilled":true}},"shipping":{"id":4000}}`))
+	})
+	defer closeServer()
+
+	order, err := client.Orders().Get(context.Background(), 2000003508)
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if order.Status != OrderPaid || order.OrderItems[0].Item.ID != "MLA123" || order.OrderItems[0].Quantity != 2 ||
+		order.Buyer.Nickname != "TEST_BUYER" || order.Payments[0].TransactionAmount != 50 ||
+		order.Feedback.Sale != nil || order.Feedback.Purchase.Rating != "positive" || order.Shipping.ID != 4000 {
+		log.Printf("Error: unexpected order %+v\n", order)
+		t.FailNow()
+	}
+}
+
+func Test_Orders_Search_sends_the_filters(t *testing.T) {
+
+	var query string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"results":[{"id":1}],"paging":{"total":1,"offset":0,"limit":50}}`))
+	})
+	defer closeServer()
+
+	from := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	page, err := client.Orders().Search(context.Background(), OrderSearch{
+		Status:      OrderPaid,
+		CreatedFrom: from,
+		Tags:        []string{"not_delivered", "paid"},
+		Sort:        OrderSortDateDesc,
+		Limit:       50,
+	})
+
+	if err != nil || len(page.Results) != 1 || page.Paging.Total != 1 {
+		log.Printf("Error: unexpected page %+v %v\n", page, err)
+		t.FailNow()
+	}
+
+	expected := "limit=50&order.date_created.from=2016-03-01T00%3A00%3A00.000%2B00%3A00&order.status=paid&seller=214509008&sort=date_desc&tags=not_delivered%2Cpaid"
+	if query != expected {
+		log.Printf("Error: unexpected query %s\n", query)
+		t.FailNow()
+	}
+}
+
+func Test_Orders_Iterate_walks_every_page_by_offset(t *testing.T) {
+
+	var offsets []string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, strconv.Itoa(offset))
+
+		var results []string
+		for id := offset; id < offset+2 && id < 5; id++ {
+			results = append(results, fmt.Sprintf(`{"id":%d}`, id))
+		}
+		fmt.Fprintf(w, `{"results":[%s],"paging":{"total":5,"offset":%d,"limit":2}}`, strings.Join(results, ","), offset)
+	})
+	defer closeServer()
+
+	orders := client.Orders().Iterate(context.Background(), OrderSearch{Limit: 2})
+
+	var ids []int64
+	for orders.Next() {
+		ids = append(ids, orders.Order().ID)
+	}
+
+	if orders.Err() != nil || fmt.Sprint(ids) != "[0 1 2 3 4]" || fmt.Sprint(offsets) != "[0 2 4]" {
+		log.Printf("Error: unexpected orders %v, offsets %v or error %v\n", ids, offsets, orders.Err())
+		t.FailNow()
+	}
+}
+
+func Test_Orders_Iterate_walks_every_page_by_scroll_id(t *testing.T) {
+
+	var scrollIDs []string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+
+		scrollID := r.URL.Query().Get("scroll_id")
+		scrollIDs = append(scrollIDs, scrollID)
+
+		if r.URL.Query().Get("search_type") != "scan" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch scrollID {
+		case "":
+			w.Write([]byte(`{"results":[{"id":1},{"id":2}],"paging":{"total":3},"scroll_id":"first"}`))
+		case "first":
+			w.Write([]byte(`{"results":[{"id":3}],"paging":{"total":3},"scroll_id":"second"}`))
+		default:
+			w.Write([]byte(`{"results":[],"paging":{"total":3},"scroll_id":"third"}`))
+		}
+	})
+	defer closeServer()
+
+	orders := client.Orders().Iterate(context.Background(), OrderSearch{Scroll: true})
+
+	var ids []int64
+	for orders.Next() {
+		ids = append(ids, orders.Order().ID)
+	}
+
+	if orders.Err() != nil || fmt.Sprint(ids) != "[1 2 3]" || fmt.Sprint(scrollIDs) != "[ first second]" {
+		log.Printf("Error: unexpected orders %v, scroll ids %v or error %v\n", ids, scrollIDs, orders.Err())
+		t.FailNow()
+	}
+}
+
+func Test_Orders_Iterate_stops_on_errors(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"forbidden","error":"forbidden","status":403}`))
+	})
+	defer closeServer()
+
+	orders := client.Orders().Iterate(context.Background(), OrderSearch{Seller: 1})
+
+	if orders.Next() || !IsForbidden(orders.Err()) || orders.Next() {
+		log.Printf("Error: the iteration should have stopped with the error, obtained %v\n", orders.Err())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*MaxPictureSize is the biggest picture, in bytes, mercadolibre accepts.*/
+const MaxPictureSize = 10 << 20
+
+var (
+	ErrPictureTooLarge    = fmt.Errorf("the picture is bigger than %d bytes", MaxPictureSize)
+	ErrEmptyPicture       = errors.New("the picture is empty")
+	ErrUnsupportedPicture = errors.New("the picture is not an image")
+)
+
+/*UploadProgress is called while a picture is being sent, with the bytes sent so far out of the total of the request.*/
+type UploadProgress func(sent int64, total int64)
+
+/*UploadedPicture is a picture uploaded to mercadolibre, which can be attached to items by its ID.*/
+type UploadedPicture struct {
+	ID         string    `json:"id"`
+	MaxSize    string    `json:"max_size"`
+	Variations []Picture `json:"variations"` // The sizes the picture was resized to
+}
+
+/*PicturesService uploads pictures and attaches them to items.*/
+type PicturesService struct {
+	client *Client
+}
+
+/*Pictures returns the service to manage pictures on behalf of the user of the client.*/
+func (client *Client) Pictures() *PicturesService {
+	return &PicturesService{client: client}
+}
+
+/*UploadFile uploads the picture found at path. See Upload.*/
+func (service *PicturesService) UploadFile(ctx context.Context, path string, progress UploadProgress) (*UploadedPicture, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return service.Upload(ctx, filepath.Base(path), file, progress)
+}
+
+/*
+Upload uploads the picture read from r, as a multipart form. The picture is read before sending it so
+its size can be checked: ErrPictureTooLarge is returned when it exceeds MaxPictureSize and ErrUnsupportedPicture when
+it is not an image. progress, when not nil, is called as the request is sent, starting over on each attempt. Like any POST,
+the upload is only retried when ctx carries an idempotency key (see WithIdempotencyKey).
+*/
+func (service *PicturesService) Upload(ctx context.Context, filename string, r io.Reader, progress UploadProgress) (*UploadedPicture, error) {
+
+	picture, err := ioutil.ReadAll(io.LimitReader(r, MaxPictureSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(picture) == 0 {
+		return nil, ErrEmptyPicture
+	}
+
+	if len(picture) > MaxPictureSize {
+		return nil, ErrPictureTooLarge
+	}
+
+	contentType := http.DetectContentType(picture)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, ErrUnsupportedPicture
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	header.Set("Content-Type", contentType)
+
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(picture)
+
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	resp, err := httpErrorHandler(ctx, service.client, "/pictures/items/upload", HTTPUpload{contentType: form.FormDataContentType(), body: body.Bytes(), progress: progress})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	uploaded := new(UploadedPicture)
+	if err := json.NewDecoder(resp.Body).Decode(uploaded); err != nil {
+		return nil, err
+	}
+
+	return uploaded, nil
+}
+
+/*Attach adds an uploaded picture to an item, after the pictures it already has.*/
+func (service *PicturesService) Attach(ctx context.Context, itemID string, pictureID string) (*Picture, error) {
+
+	picture := new(Picture)
+	if err := service.client.doJSON(ctx, http.MethodPost, "/items/"+pathID(itemID)+"/pictures", Picture{ID: pictureID}, picture); err != nil {
+		return nil, err
+	}
+
+	return picture, nil
+}
+
+/*HTTPUpload sends a body which is not JSON, i.e. a multipart form, reporting the progress of each attempt.*/
+type HTTPUpload struct {
+	contentType string
+	body        []byte
+	progress    UploadProgress
+}
+
+func (callback HTTPUpload) NewRequest(ctx context.Context, url string) (*http.Request, error) {
+
+	req, err := newHTTPRequest(ctx, http.MethodPost, url, callback.contentType, callback.newBody())
+	if err != nil {
+		return nil, err
+	}
+
+	req.ContentLength = int64(len(callback.body))
+
+	//Allows the request to be retried, reporting the progress of the new attempt from the start
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(callback.newBody()), nil
+	}
+
+	return req, nil
+}
+
+func (callback HTTPUpload) newBody() io.Reader {
+
+	var body io.Reader = bytes.NewReader(callback.body)
+	if callback.progress != nil {
+		body = &progressReader{reader: body, total: int64(len(callback.body)), progress: callback.progress}
+	}
+
+	return body
+}
+
+type progressReader struct {
+	reader   io.Reader
+	sent     int64
+	total    int64
+	progress UploadProgress
+}
+
+func (reader *progressReader) Read(p []byte) (int, error) {
+
+	n, err := reader.reader.Read(p)
+
+	if n > 0 {
+		reader.sent += int64(n)
+		reader.progress(reader.sent, reader.total)
+	}
+
+	return n, err
+}
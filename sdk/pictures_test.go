@@ -0,0 +1,179 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testPicture = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 1024)...)
+
+func Test_Pictures_UploadFile_sends_a_multipart_form_and_reports_progress(t *testing.T) {
+
+	var filename, contentType string
+	var received []byte
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodPost || r.URL.Path != "/pictures/items/upload" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		filename, contentType = header.Filename, header.Header.Get("Content-Type")
+		received, _ = ioutil.ReadAll(file)
+
+		w.Write([]byte(`{"id":"123-MLA456_012016","max_size":"500x500","variations":[{"size":"500x500","url":"http://mla.com/1.png","secure_url":"https://mla.com/1.png"}]}`))
+	})
+	defer closeServer()
+
+	path := filepath.Join(t.TempDir(), "picture.png")
+	os.WriteFile(path, testPicture, 0600)
+
+	var sent, total int64
+	uploaded, err := client.Pictures().UploadFile(context.Background(), path, func(s int64, t int64) {
+		sent, total = s, t
+	})
+
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if uploaded.ID != "123-MLA456_012016" || len(uploaded.Variations) != 1 || uploaded.Variations[0].SecureURL != "https://mla.com/1.png" {
+		log.Printf("Error: unexpected uploaded picture %+v\n", uploaded)
+		t.FailNow()
+	}
+
+	if filename != "picture.png" || contentType != "image/png" || !bytes.Equal(received, testPicture) {
+		log.Printf("Error: unexpected part %s %s of %d bytes\n", filename, contentType, len(received))
+		t.FailNow()
+	}
+
+	if total == 0 || sent != total {
+		log.Printf("Error: the progress should have reached the total, obtained %d of %d\n", sent, total)
+		t.FailNow()
+	}
+}
+
+func Test_Pictures_Upload_validates_the_picture_before_sending_it(t *testing.T) {
+
+	calls := 0
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+	defer closeServer()
+
+	pictures := client.Pictures()
+	ctx := context.Background()
+
+	tooLarge := append(append([]byte{}, testPicture...), make([]byte, MaxPictureSize)...)
+	if _, err := pictures.Upload(ctx, "large.png", bytes.NewReader(tooLarge), nil); !errors.Is(err, ErrPictureTooLarge) {
+		log.Printf("Error: ErrPictureTooLarge was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if _, err := pictures.Upload(ctx, "text.png", strings.NewReader("not a picture"), nil); !errors.Is(err, ErrUnsupportedPicture) {
+		log.Printf("Error: ErrUnsupportedPicture was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if _, err := pictures.Upload(ctx, "empty.png", strings.NewReader(""), nil); !errors.Is(err, ErrEmptyPicture) {
+		log.Printf("Error: ErrEmptyPicture was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if calls != 0 {
+		log.Printf("Error: invalid pictures should not be sent\n")
+		t.FailNow()
+	}
+}
+
+func Test_Pictures_Attach_adds_the_picture_to_the_item(t *testing.T) {
+
+	var call string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		call = r.Method + " " + r.URL.Path + " " + string(body)
+		w.Write([]byte(`{"id":"123-MLA456_012016","url":"http://mla.com/1.png"}`))
+	})
+	defer closeServer()
+
+	picture, err := client.Pictures().Attach(context.Background(), "MLA123", "123-MLA456_012016")
+
+	if err != nil || picture.URL != "http://mla.com/1.png" {
+		log.Printf("Error: %v\n", err)
+		t.FailNow()
+	}
+
+	if call != `POST /items/MLA123/pictures {"id":"123-MLA456_012016"}` {
+		log.Printf("Error: unexpected call %s\n", call)
+		t.FailNow()
+	}
+}
+
+func Test_Pictures_Upload_with_progress_is_retried(t *testing.T) {
+
+	calls := 0
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if _, _, err := r.FormFile("file"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"id":"123-MLA456_012016"}`))
+	})
+	defer closeServer()
+
+	client.retryPolicy = newTestRetryPolicy()
+
+	var sent, total int64
+	ctx := WithIdempotencyKey(context.Background(), "upload-1")
+
+	uploaded, err := client.Pictures().Upload(ctx, "picture.png", bytes.NewReader(testPicture), func(s int64, t int64) {
+		sent, total = s, t
+	})
+
+	if err != nil || uploaded.ID != "123-MLA456_012016" || calls != 2 {
+		log.Printf("Error: the upload should have been retried, obtained %v after %d calls\n", err, calls)
+		t.FailNow()
+	}
+
+	if total == 0 || sent != total {
+		log.Printf("Error: the progress of the last attempt should have reached the total, obtained %d of %d\n", sent, total)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+/*Status of the questions.*/
+const (
+	QuestionUnanswered       = "UNANSWERED"
+	QuestionAnswered         = "ANSWERED"
+	QuestionClosedUnanswered = "CLOSED_UNANSWERED"
+	QuestionUnderReview      = "UNDER_REVIEW"
+	QuestionBanned           = "BANNED"
+	QuestionDeleted          = "DELETED"
+)
+
+/*MaxAnswerLength is the max number of characters of an answer.*/
+const MaxAnswerLength = 2000
+
+var (
+	ErrEmptyAnswer   = errors.New("the answer is empty")
+	ErrAnswerTooLong = fmt.Errorf("the answer is longer than %d characters", MaxAnswerLength)
+)
+
+/*Question asked by a buyer about an item.*/
+type Question struct {
+	ID          int64      `json:"id"`
+	ItemID      string     `json:"item_id"`
+	SellerID    int64      `json:"seller_id"`
+	Text        string     `json:"text"`
+	Status      string     `json:"status"`
+	DateCreated *time.Time `json:"date_created"`
+	Hold        bool       `json:"hold"`
+	Answer      *Answer    `json:"answer"` // nil until the question is answered
+	From        struct {
+		ID int64 `json:"id"`
+	} `json:"from"`
+}
+
+/*Answer given by the seller to a question.*/
+type Answer struct {
+	Text        string     `json:"text"`
+	Status      string     `json:"status"`
+	DateCreated *time.Time `json:"date_created"`
+}
+
+/*
+QuestionSearch filters the questions returned by Search. Either Item or Seller is required by mercadolibre;
+when none is set, the questions received by the user of the client are searched. Zero fields are not sent.
+*/
+type QuestionSearch struct {
+	Item   string
+	Seller int64
+	From   int64 // Id of the user who asked
+	Status string
+	Offset int
+	Limit  int
+}
+
+/*QuestionPage is a page of the results of a question search.*/
+type QuestionPage struct {
+	Questions []Question
+	Paging    Paging
+}
+
+/*QuestionsService gives typed access to the questions asked to the user of the client, and lets the user answer them.*/
+type QuestionsService struct {
+	client *Client
+}
+
+/*Questions returns the service to manage the questions received by the user of the client.*/
+func (client *Client) Questions() *QuestionsService {
+	return &QuestionsService{client: client}
+}
+
+/*Search retrieves a page of questions, the newest first.*/
+func (service *QuestionsService) Search(ctx context.Context, search QuestionSearch) (*QuestionPage, error) {
+
+	query := url.Values{}
+	query.Set("api_version", "4")
+	query.Set("sort_fields", "date_created")
+	query.Set("sort_types", "DESC")
+
+	if search.Item == "" && search.Seller == 0 {
+		seller, err := service.client.UserID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		search.Seller = seller
+	}
+
+	if search.Item != "" {
+		query.Set("item", search.Item)
+	}
+	if search.Seller != 0 {
+		query.Set("seller_id", strconv.FormatInt(search.Seller, 10))
+	}
+	if search.From != 0 {
+		query.Set("from", strconv.FormatInt(search.From, 10))
+	}
+	if search.Status != "" {
+		query.Set("status", search.Status)
+	}
+	if search.Offset > 0 {
+		query.Set("offset", strconv.Itoa(search.Offset))
+	}
+	if search.Limit > 0 {
+		query.Set("limit", strconv.Itoa(search.Limit))
+	}
+
+	var result struct {
+		Questions []Question `json:"questions"`
+		Total     int        `json:"total"`
+		Limit     int        `json:"limit"`
+	}
+
+	if err := service.client.getJSON(ctx, "/questions/search?"+query.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	return &QuestionPage{Questions: result.Questions, Paging: Paging{Total: result.Total, Offset: search.Offset, Limit: result.Limit}}, nil
+}
+
+/*Get retrieves the question with the given id.*/
+func (service *QuestionsService) Get(ctx context.Context, id int64) (*Question, error) {
+
+	question := new(Question)
+	if err := service.client.getJSON(ctx, "/questions/"+strconv.FormatInt(id, 10)+"?api_version=4", question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+/*
+Answer answers a question and returns it along with its answer. ErrEmptyAnswer or ErrAnswerTooLong are returned
+without calling the API when text is blank or longer than MaxAnswerLength characters.
+*/
+func (service *QuestionsService) Answer(ctx context.Context, questionID int64, text string) (*Question, error) {
+
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyAnswer
+	}
+
+	if utf8.RuneCountInString(text) > MaxAnswerLength {
+		return nil, ErrAnswerTooLong
+	}
+
+	answer := struct {
+		QuestionID int64  `json:"question_id"`
+		Text       string `json:"text"`
+	}{questionID, text}
+
+	question := new(Question)
+	if err := service.client.doJSON(ctx, http.MethodPost, "/answers", answer, question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+/*Delete deletes a question asked about an item of the user.*/
+func (service *QuestionsService) Delete(ctx context.Context, id int64) error {
+	return service.client.doJSON(ctx, http.MethodDelete, "/questions/"+strconv.FormatInt(id, 10), nil, nil)
+}
+
+/*BlockBuyer prevents a buyer from asking questions about the items of the user.*/
+func (service *QuestionsService) BlockBuyer(ctx context.Context, buyerID int64) error {
+
+	seller, err := service.client.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	buyer := struct {
+		UserID int64 `json:"user_id"`
+	}{buyerID}
+
+	return service.client.doJSON(ctx, http.MethodPost, "/users/"+strconv.FormatInt(seller, 10)+"/questions_blacklist", buyer, nil)
+}
+
+/*UnblockBuyer allows a blocked buyer to ask questions again.*/
+func (service *QuestionsService) UnblockBuyer(ctx context.Context, buyerID int64) error {
+
+	seller, err := service.client.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	return service.client.doJSON(ctx, http.MethodDelete, "/users/"+strconv.FormatInt(seller, 10)+"/questions_blacklist/"+strconv.FormatInt(buyerID, 10), nil, nil)
+}
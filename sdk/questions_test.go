@@ -0,0 +1,141 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_Questions_Search_returns_the_questions_of_the_seller(t *testing.T) {
+
+	var query string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"total":2,"limit":50,"questions":[
+			{"id":1,"item_id":"MLA123","text":"Is it new?","status":"UNANSWERED","from":{"id":123}},
+			{"id":2,"item_id":"MLA123","text":"Color?","status":"ANSWERED","answer":{"text":"Black","status":"ACTIVE"}}]}`))
+	})
+	defer closeServer()
+
+	page, err := client.Questions().Search(context.Background(), QuestionSearch{Status: QuestionUnanswered, Limit: 50})
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if query != "api_version=4&limit=50&seller_id=214509008&sort_fields=date_created&sort_types=DESC&status=UNANSWERED" {
+		log.Printf("Error: unexpected query %s\n", query)
+		t.FailNow()
+	}
+
+	if page.Paging.Total != 2 || len(page.Questions) != 2 || page.Questions[0].From.ID != 123 ||
+		page.Questions[0].Answer != nil || page.Questions[1].Answer.Text != "Black" {
+		log.Printf("Error: unexpected page %+v\n", page)
+		t.FailNow()
+	}
+}
+
+func Test_Questions_Answer_validates_the_text_before_posting_it(t *testing.T) {
+
+	var calls []string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"id":1,"status":"ANSWERED","answer":{"text":"Yes, it is new"}}`))
+	})
+	defer closeServer()
+
+	questions := client.Questions()
+	ctx := context.Background()
+
+	if _, err := questions.Answer(ctx, 1, "  "); !errors.Is(err, ErrEmptyAnswer) {
+		log.Printf("Error: ErrEmptyAnswer was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if _, err := questions.Answer(ctx, 1, strings.Repeat("ñ", MaxAnswerLength+1)); !errors.Is(err, ErrAnswerTooLong) {
+		log.Printf("Error: ErrAnswerTooLong was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if _, err := questions.Answer(ctx, 1, strings.Repeat("ñ", MaxAnswerLength)); err != nil {
+		log.Printf("Error: an answer of %d characters should be accepted, obtained %v\n", MaxAnswerLength, err)
+		t.FailNow()
+	}
+
+	question, err := questions.Answer(ctx, 1, "Yes, it is new")
+	if err != nil || question.Answer.Text != "Yes, it is new" {
+		log.Printf("Error: %v\n", err)
+		t.FailNow()
+	}
+
+	if len(calls) != 2 || calls[1] != `POST /answers {"question_id":1,"text":"Yes, it is new"}` {
+		log.Printf("Error: unexpected calls %v\n", calls)
+		t.FailNow()
+	}
+}
+
+func Test_Questions_Delete_and_blocking_call_their_resources(t *testing.T) {
+
+	var calls []string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`{}`))
+	})
+	defer closeServer()
+
+	questions := client.Questions()
+	ctx := context.Background()
+
+	if err := questions.Delete(ctx, 1); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if err := questions.BlockBuyer(ctx, 123); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if err := questions.UnblockBuyer(ctx, 123); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	expected := []string{
+		"DELETE /questions/1 ",
+		`POST /users/214509008/questions_blacklist {"user_id":123}`,
+		"DELETE /users/214509008/questions_blacklist/123 ",
+	}
+
+	for i := range expected {
+		if i >= len(calls) || calls[i] != expected[i] {
+			log.Printf("Error: expected call %s, obtained %v\n", expected[i], calls)
+			t.FailNow()
+		}
+	}
+}
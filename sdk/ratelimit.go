@@ -0,0 +1,237 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+/*RateLimitScope identifies the application and the user a call is made on behalf of.*/
+type RateLimitScope struct {
+	ClientID int64
+	UserID   int64 // 0 for anonymous and application clients, or while the id of the user is not known
+}
+
+/*
+RateLimiter allows throttling the calls made by every Client sharing it, before they reach mercadolibre quotas.
+Wait is called before each request is dispatched and must block until it can be sent or ctx is done.
+Throttle is called whenever the API answers 429, with the delay requested by the Retry-After header (0 if absent).
+*/
+type RateLimiter interface {
+	Wait(ctx context.Context, scope RateLimitScope) error
+	Throttle(scope RateLimitScope, retryAfter time.Duration)
+}
+
+const (
+	defaultThrottlePause = time.Second
+	throttleRecovery     = time.Minute
+	minBucketsToPrune    = 1024
+)
+
+/*
+TokenBucketLimiter is the default RateLimiter. It keeps a token bucket per application and another one per user,
+and a call has to get a token from both of them. A rate lower or equal than zero means no limit.
+
+When a 429 is received, the bucket of the user (or the one of the application for anonymous calls) is paused for the
+Retry-After delay and its rate is halved. The configured rate is restored once no 429 was received for a minute.
+*/
+type TokenBucketLimiter struct {
+	AppRate   float64 // Requests per second per application
+	AppBurst  int
+	UserRate  float64 // Requests per second per user
+	UserBurst int
+
+	mutex   sync.Mutex
+	buckets map[RateLimitScope]*tokenBucket
+	pruneAt int // Number of buckets after which the idle ones are dropped
+}
+
+func NewTokenBucketLimiter(appRate float64, appBurst int, userRate float64, userBurst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{AppRate: appRate, AppBurst: appBurst, UserRate: userRate, UserBurst: userBurst}
+}
+
+func (limiter *TokenBucketLimiter) Wait(ctx context.Context, scope RateLimitScope) error {
+
+	limiter.mutex.Lock()
+
+	now := time.Now()
+	var reserved []*tokenBucket
+	var delay time.Duration
+
+	for _, bucket := range limiter.bucketsFor(scope) {
+		if d := bucket.reserve(now); d > delay {
+			delay = d
+		}
+		reserved = append(reserved, bucket)
+	}
+
+	limiter.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, delay); err != nil {
+		limiter.mutex.Lock()
+		for _, bucket := range reserved {
+			bucket.cancel()
+		}
+		limiter.mutex.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (limiter *TokenBucketLimiter) Throttle(scope RateLimitScope, retryAfter time.Duration) {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if retryAfter <= 0 {
+		retryAfter = defaultThrottlePause
+	}
+
+	buckets := limiter.bucketsFor(scope)
+	if len(buckets) > 0 {
+		buckets[len(buckets)-1].throttle(time.Now(), retryAfter)
+	}
+}
+
+/*bucketsFor returns the application bucket followed by the user one, skipping the unlimited ones. The mutex must be held.*/
+func (limiter *TokenBucketLimiter) bucketsFor(scope RateLimitScope) []*tokenBucket {
+
+	if limiter.buckets == nil {
+		limiter.buckets = make(map[RateLimitScope]*tokenBucket)
+	}
+
+	var buckets []*tokenBucket
+
+	if limiter.AppRate > 0 {
+		buckets = append(buckets, limiter.bucket(RateLimitScope{ClientID: scope.ClientID}, limiter.AppRate, limiter.AppBurst))
+	}
+
+	if limiter.UserRate > 0 && scope.UserID != 0 {
+		buckets = append(buckets, limiter.bucket(scope, limiter.UserRate, limiter.UserBurst))
+	}
+
+	return buckets
+}
+
+func (limiter *TokenBucketLimiter) bucket(key RateLimitScope, rate float64, burst int) *tokenBucket {
+
+	bucket := limiter.buckets[key]
+
+	if bucket == nil {
+		limiter.pruneIdle()
+		if burst < 1 {
+			burst = 1
+		}
+		bucket = &tokenBucket{configuredRate: rate, rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+		limiter.buckets[key] = bucket
+	}
+
+	return bucket
+}
+
+/*
+pruneIdle drops the buckets which are full and not throttled, since they behave as new ones, once there are too many of them.
+The mutex must be held.
+*/
+func (limiter *TokenBucketLimiter) pruneIdle() {
+
+	if len(limiter.buckets) < limiter.pruneAt || len(limiter.buckets) < minBucketsToPrune {
+		return
+	}
+
+	now := time.Now()
+	for key, bucket := range limiter.buckets {
+		if bucket.idle(now) {
+			delete(limiter.buckets, key)
+		}
+	}
+
+	limiter.pruneAt = 2 * len(limiter.buckets)
+}
+
+type tokenBucket struct {
+	configuredRate float64
+	rate           float64
+	burst          float64
+	tokens         float64
+	last           time.Time
+	pausedUntil    time.Time
+	throttledAt    time.Time
+}
+
+/*reserve takes a token from the bucket and returns how long the caller has to wait before using it.*/
+func (bucket *tokenBucket) reserve(now time.Time) time.Duration {
+
+	if bucket.rate < bucket.configuredRate && now.Sub(bucket.throttledAt) > throttleRecovery {
+		bucket.rate = bucket.configuredRate
+	}
+
+	if now.After(bucket.last) {
+		bucket.tokens = math.Min(bucket.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.rate)
+		bucket.last = now
+	}
+
+	bucket.tokens--
+
+	var delay time.Duration
+	if bucket.tokens < 0 {
+		delay = time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+	}
+
+	if paused := bucket.pausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+
+	return delay
+}
+
+/*idle reports whether the bucket would be full and at its configured rate by now.*/
+func (bucket *tokenBucket) idle(now time.Time) bool {
+
+	if now.Before(bucket.pausedUntil) {
+		return false
+	}
+
+	if bucket.rate < bucket.configuredRate && now.Sub(bucket.throttledAt) <= throttleRecovery {
+		return false
+	}
+
+	return bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.rate >= bucket.burst
+}
+
+/*cancel gives back a token taken by reserve which is not going to be used.*/
+func (bucket *tokenBucket) cancel() {
+	bucket.tokens = math.Min(bucket.burst, bucket.tokens+1)
+}
+
+func (bucket *tokenBucket) throttle(now time.Time, pause time.Duration) {
+
+	if until := now.Add(pause); until.After(bucket.pausedUntil) {
+		bucket.pausedUntil = until
+	}
+
+	bucket.rate = math.Max(bucket.rate/2, bucket.configuredRate/10)
+	bucket.throttledAt = now
+}
@@ -0,0 +1,141 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_TokenBucketLimiter_blocks_until_the_context_is_done_when_the_bucket_is_empty(t *testing.T) {
+
+	limiter := NewTokenBucketLimiter(0, 0, 1, 1)
+	scope := RateLimitScope{ClientID: CLIENT_ID, UserID: 214509008}
+
+	if err := limiter.Wait(context.Background(), scope); err != nil {
+		log.Printf("Error: the first call should not have been throttled %s\n", err)
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, scope); !errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Error: the second call should have been throttled, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_TokenBucketLimiter_keeps_a_bucket_per_user(t *testing.T) {
+
+	limiter := NewTokenBucketLimiter(0, 0, 1, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	for _, userID := range []int64{1, 2, 3} {
+		if err := limiter.Wait(ctx, RateLimitScope{ClientID: CLIENT_ID, UserID: userID}); err != nil {
+			log.Printf("Error: users should not share their bucket %s\n", err)
+			t.FailNow()
+		}
+	}
+}
+
+func Test_TokenBucketLimiter_pauses_the_bucket_when_a_429_is_received(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	limiter := NewTokenBucketLimiter(100, 10, 0, 0)
+	client := newClient(MeliConfig{ClientID: CLIENT_ID, HTTPClient: MeliHTTPClient{Client: server.Client()}, RateLimiter: limiter})
+	client.apiURL = server.URL
+
+	if _, err := client.Get("/sites"); !IsRateLimited(err) {
+		log.Printf("Error: a rate limited error was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, client.rateLimitScope()); !errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Error: the bucket should have been paused, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_TokenBucketLimiter_limits_the_users_of_clients_built_from_a_token(t *testing.T) {
+
+	limiter := NewTokenBucketLimiter(0, 0, 1, 1)
+	config := MeliConfig{ClientID: CLIENT_ID, Secret: CLIENT_SECRET, HTTPClient: MockHttpClient{}, RateLimiter: limiter, DisableCache: true}
+
+	client, err := NewClientFromToken(config, Authorization{AccessToken: "valid token", ExpiresIn: 6 * time.Hour, UserID: 214509008})
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	scope := client.rateLimitScope()
+	if scope.UserID != 214509008 {
+		log.Printf("Error: the scope should carry the user of the token, obtained %+v\n", scope)
+		t.FailNow()
+	}
+
+	if err := limiter.Wait(context.Background(), scope); err != nil {
+		log.Printf("Error: the first call should not have been throttled %s\n", err)
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, scope); !errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Error: the user of the token should have been limited, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_TokenBucketLimiter_drops_idle_buckets(t *testing.T) {
+
+	limiter := NewTokenBucketLimiter(0, 0, 1, 1)
+
+	for userID := int64(1); userID <= minBucketsToPrune; userID++ {
+		limiter.Wait(context.Background(), RateLimitScope{ClientID: CLIENT_ID, UserID: userID})
+	}
+
+	//Every bucket but the first one refilled long ago
+	for key, bucket := range limiter.buckets {
+		if key.UserID != 1 {
+			bucket.last = bucket.last.Add(-time.Hour)
+		}
+	}
+
+	limiter.Wait(context.Background(), RateLimitScope{ClientID: CLIENT_ID, UserID: minBucketsToPrune + 1})
+
+	if len(limiter.buckets) != 2 || limiter.buckets[RateLimitScope{ClientID: CLIENT_ID, UserID: 1}] == nil {
+		log.Printf("Error: only the busy and the new buckets should have been kept, %d buckets are kept\n", len(limiter.buckets))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRefreshFraction   = 0.8
+	defaultRefreshInterval   = time.Minute
+	defaultRefreshJitter     = 0.1
+	defaultRefreshMaxBackoff = 30 * time.Minute
+)
+
+/*
+BackgroundRefresher refreshes the tokens of every client of a ClientRegistry before they expire,
+so the refresh does not delay the calls made on behalf of the users, and the refresh tokens of idle users are kept alive.
+A refresh counts as a use of the client, so the registry does not evict it for being idle for its ttl. The registry
+still evicts the least recently used clients beyond its max size, which are not refreshed anymore.
+
+A token is refreshed once the given Fraction of its lifetime has elapsed, minus a random Jitter so the refreshes of
+the clients authorized at the same time are spread. A failed refresh is retried Interval later, doubling the delay on each
+failure up to MaxBackoff. The fields must not be changed once the refresher was started.
+*/
+type BackgroundRefresher struct {
+	Registry   *ClientRegistry // DefaultClientRegistry when nil
+	Fraction   float64         // Fraction (0 to 1) of the lifetime of a token after which it is refreshed. 0.8 when zero
+	Interval   time.Duration   // How often the clients are checked. A minute when zero
+	Jitter     float64         // Fraction (0 to 1) of the refresh time which is randomized. 0.1 when zero
+	MaxBackoff time.Duration   // Upper bound for the delay between failed refreshes. 30 minutes when zero
+
+	// OnRefreshed is called after each refresh, i.e. to persist the rotated refresh token.
+	OnRefreshed func(client *Client, auth Authorization)
+
+	// OnError is called after each failed refresh.
+	OnError func(client *Client, err error)
+
+	mutex    sync.Mutex
+	stop     chan struct{} // nil while the refresher is not running
+	done     chan struct{}
+	schedule map[*Client]*refreshSchedule
+}
+
+/*refreshSchedule is the next refresh of a client, computed from the token it had at that moment.*/
+type refreshSchedule struct {
+	expiresAt time.Time
+	due       time.Time
+	failures  uint
+}
+
+/*
+Start starts refreshing the tokens in background. It does nothing if the refresher is already running.
+A stopped refresher can be started again.
+*/
+func (refresher *BackgroundRefresher) Start() {
+
+	refresher.mutex.Lock()
+	defer refresher.mutex.Unlock()
+
+	if refresher.stop != nil {
+		return
+	}
+
+	refresher.stop = make(chan struct{})
+	refresher.done = make(chan struct{})
+	refresher.schedule = make(map[*Client]*refreshSchedule)
+
+	go refresher.run(refresher.stop, refresher.done)
+}
+
+/*Stop stops the refresher and returns once it stopped checking the clients. It does nothing if the refresher is not running.*/
+func (refresher *BackgroundRefresher) Stop() {
+
+	refresher.mutex.Lock()
+	defer refresher.mutex.Unlock()
+
+	if refresher.stop == nil {
+		return
+	}
+
+	close(refresher.stop)
+	<-refresher.done
+
+	refresher.stop = nil
+	refresher.done = nil
+}
+
+func (refresher *BackgroundRefresher) run(stop chan struct{}, done chan struct{}) {
+
+	defer close(done)
+
+	ticker := time.NewTicker(refresher.interval())
+	defer ticker.Stop()
+
+	for {
+		refresher.refreshDue(stop)
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+/*refreshDue refreshes the tokens of the clients which are due, one at a time. The time is told by the clock of each client.*/
+func (refresher *BackgroundRefresher) refreshDue(stop chan struct{}) {
+
+	registry := refresher.Registry
+	if registry == nil {
+		registry = DefaultClientRegistry
+	}
+
+	clients := registry.clients()
+	registered := make(map[*Client]bool, len(clients))
+
+	for _, client := range clients {
+
+		registered[client] = true
+
+		auth := client.Authorization()
+		if (auth.RefreshToken == "" && !client.application) || client.NeedsReauthorization() {
+			continue
+		}
+
+		schedule := refresher.schedule[client]
+		if schedule == nil || !schedule.expiresAt.Equal(auth.expiresAt()) {
+			schedule = &refreshSchedule{expiresAt: auth.expiresAt(), due: refresher.dueTime(auth)}
+			refresher.schedule[client] = schedule
+		}
+
+		now := client.now()
+		if now.Before(schedule.due) {
+			continue
+		}
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if refresher.refresh(client, schedule, now, stop) {
+			registry.touch(client)
+		}
+	}
+
+	//The clients evicted from the registry are forgotten
+	for client := range refresher.schedule {
+		if !registered[client] {
+			delete(refresher.schedule, client)
+		}
+	}
+}
+
+/*refresh refreshes the token of client, if it was not refreshed in the meantime, and tells whether it succeeded.*/
+func (refresher *BackgroundRefresher) refresh(client *Client, schedule *refreshSchedule, now time.Time, stop chan struct{}) bool {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	//The refresh is abandoned when the refresher is stopped
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	//The token may have been refreshed by a call made in the meantime
+	err := client.refreshTokenOnce(ctx, func(auth Authorization) bool {
+		return auth.expiresAt().Equal(schedule.expiresAt)
+	})
+
+	if err != nil {
+		schedule.due = now.Add(refresher.backoff(schedule.failures))
+		schedule.failures++
+		if refresher.OnError != nil {
+			refresher.OnError(client, err)
+		}
+		return false
+	}
+
+	auth := client.Authorization()
+	schedule.expiresAt = auth.expiresAt()
+	schedule.due = refresher.dueTime(auth)
+	schedule.failures = 0
+
+	if refresher.OnRefreshed != nil {
+		refresher.OnRefreshed(client, auth)
+	}
+
+	return true
+}
+
+/*dueTime returns when the given token has to be refreshed.*/
+func (refresher *BackgroundRefresher) dueTime(auth Authorization) time.Time {
+
+	fraction := refresher.Fraction
+	if fraction <= 0 || fraction > 1 {
+		fraction = defaultRefreshFraction
+	}
+
+	jitter := refresher.Jitter
+	if jitter <= 0 || jitter > 1 {
+		jitter = defaultRefreshJitter
+	}
+
+	delay := time.Duration(float64(auth.ExpiresIn) * fraction * (1 - jitter*rand.Float64()))
+
+	return auth.expiresAt().Add(-auth.ExpiresIn).Add(delay)
+}
+
+func (refresher *BackgroundRefresher) backoff(failures uint) time.Duration {
+
+	maxBackoff := refresher.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRefreshMaxBackoff
+	}
+
+	delay := refresher.interval() << failures
+	if delay <= 0 || delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
+
+func (refresher *BackgroundRefresher) interval() time.Duration {
+
+	if refresher.Interval <= 0 {
+		return defaultRefreshInterval
+	}
+
+	return refresher.Interval
+}
@@ -0,0 +1,193 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*newAgedClient returns a client whose token was received the given seconds ago and lasts 10800 seconds.*/
+func newAgedClient(refresher TokenRefresher, age int64) *Client {
+
+	client := newClient(MeliConfig{ClientID: CLIENT_ID, Secret: CLIENT_SECRET, HTTPClient: MockHttpClient{}, TokenRefresher: refresher})
+	client.setAuthorization(Authorization{
+		AccessToken:  "old token",
+		RefreshToken: "valid refresh token",
+		ExpiresIn:    10800 * time.Second,
+		ReceivedAt:   time.Now().Unix() - age,
+	})
+
+	return client
+}
+
+func Test_tokens_are_refreshed_in_background_once_the_fraction_of_their_lifetime_elapsed(t *testing.T) {
+
+	registry := NewClientRegistry(10, 0)
+	due := registry.add(ClientKey{CLIENT_ID, 1}, "", newAgedClient(nil, 9000))
+	fresh := registry.add(ClientKey{CLIENT_ID, 2}, "", newAgedClient(nil, 60))
+
+	refreshed := make(chan Authorization, 1)
+
+	refresher := &BackgroundRefresher{
+		Registry: registry,
+		Fraction: 0.8,
+		Interval: 10 * time.Millisecond,
+		OnRefreshed: func(client *Client, auth Authorization) {
+			if client == due {
+				refreshed <- auth
+			}
+		},
+	}
+
+	refresher.Start()
+	defer refresher.Stop()
+
+	select {
+	case auth := <-refreshed:
+		if auth.AccessToken != "valid token" {
+			log.Printf("Error: the new token should have been given to OnRefreshed, obtained %s\n", auth.AccessToken)
+			t.FailNow()
+		}
+	case <-time.After(2 * time.Second):
+		log.Printf("Error: the token should have been refreshed in background\n")
+		t.FailNow()
+	}
+
+	refresher.Stop()
+
+	if fresh.Authorization().AccessToken != "old token" {
+		log.Printf("Error: a token which is not due should not have been refreshed\n")
+		t.FailNow()
+	}
+}
+
+func Test_failed_background_refreshes_are_retried_with_backoff(t *testing.T) {
+
+	var calls int32
+	refresher := &BackgroundRefresher{Interval: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}
+	refresher.OnError = func(client *Client, err error) { atomic.AddInt32(&calls, 1) }
+
+	failing := &MockCountingTokenRefresher{err: errors.New("refresh failed")}
+	client := newAgedClient(failing, 10000)
+
+	registry := NewClientRegistry(10, 0)
+	registry.add(ClientKey{CLIENT_ID, 1}, "", client)
+	refresher.Registry = registry
+
+	refresher.Start()
+	time.Sleep(200 * time.Millisecond)
+	refresher.Stop()
+
+	//10, 20, 40, 40... milliseconds between attempts
+	if n := atomic.LoadInt32(&calls); n < 2 || n > 8 {
+		log.Printf("Error: unexpected number of attempts %d\n", n)
+		t.FailNow()
+	}
+
+	if backoff := refresher.backoff(10); backoff != 40*time.Millisecond {
+		log.Printf("Error: the backoff should be bounded by MaxBackoff, obtained %s\n", backoff)
+		t.FailNow()
+	}
+}
+
+func Test_a_stopped_refresher_can_be_started_again(t *testing.T) {
+
+	registry := NewClientRegistry(10, 0)
+	client := registry.add(ClientKey{CLIENT_ID, 1}, "", newAgedClient(nil, 9000))
+
+	refreshed := make(chan Authorization, 1)
+
+	refresher := &BackgroundRefresher{
+		Registry: registry,
+		Interval: time.Millisecond,
+		OnRefreshed: func(client *Client, auth Authorization) {
+			select {
+			case refreshed <- auth:
+			default:
+			}
+		},
+	}
+
+	//Stopping a refresher which is not running does nothing
+	refresher.Stop()
+
+	for i := 0; i < 2; i++ {
+
+		client.setAuthorization(newAgedClient(nil, 9000).Authorization())
+
+		refresher.Start()
+
+		select {
+		case <-refreshed:
+		case <-time.After(2 * time.Second):
+			log.Printf("Error: the token should have been refreshed after starting the refresher %d times\n", i+1)
+			t.FailNow()
+		}
+
+		refresher.Stop()
+		refresher.Stop()
+	}
+}
+
+func Test_refreshed_clients_are_not_evicted_for_being_idle(t *testing.T) {
+
+	aged := newAgedClient(nil, 9000)
+	auth := aged.Authorization()
+	auth.UserID = 1
+	aged.setAuthorization(auth)
+
+	registry := NewClientRegistry(10, time.Hour)
+	client := registry.add(ClientKey{CLIENT_ID, 1}, "", aged)
+
+	//The client was last used by a call almost an hour ago
+	registry.mutex.Lock()
+	entry := registry.entries[ClientKey{CLIENT_ID, 1}].Value.(*registryEntry)
+	entry.lastUsed = time.Now().Add(-50 * time.Minute)
+	registry.mutex.Unlock()
+
+	refreshed := make(chan bool, 1)
+
+	refresher := &BackgroundRefresher{
+		Registry:    registry,
+		Interval:    10 * time.Millisecond,
+		OnRefreshed: func(refreshedClient *Client, auth Authorization) { refreshed <- refreshedClient == client },
+	}
+
+	refresher.Start()
+
+	select {
+	case <-refreshed:
+	case <-time.After(2 * time.Second):
+		log.Printf("Error: the token should have been refreshed in background\n")
+		t.FailNow()
+	}
+
+	refresher.Stop()
+
+	registry.mutex.Lock()
+	lastUsed := entry.lastUsed
+	registry.mutex.Unlock()
+
+	if time.Since(lastUsed) > time.Minute {
+		log.Printf("Error: the refresh should have marked the client as used, it was last used %s ago\n", time.Since(lastUsed))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRegistrySize = 1000
+	defaultRegistryTTL  = 24 * time.Hour
+)
+
+/*
+DefaultClientRegistry is the registry used by MeliClient when MeliConfig.Registry is nil.
+It keeps up to 1000 clients, and evicts the ones which were not used for a day.
+*/
+var DefaultClientRegistry = NewClientRegistry(defaultRegistrySize, defaultRegistryTTL)
+
+/*ClientKey identifies the client of a mercadolibre user for a given application.*/
+type ClientKey struct {
+	ClientID int64
+	UserID   int64
+}
+
+type codeKey struct {
+	clientID int64
+	code     string
+}
+
+/*
+ClientRegistry caches the authorized clients, so the same Client is returned each time one is requested for a given user.
+This matters because the tokens of a user are refreshed by a single client at a time.
+
+The least recently used clients are evicted once maxSize clients are cached, and so are the ones which were not used for ttl.
+A maxSize or ttl lower or equal than zero means no limit. It is safe for concurrent use.
+*/
+type ClientRegistry struct {
+	maxSize int
+	ttl     time.Duration
+
+	mutex   sync.Mutex
+	entries map[ClientKey]*list.Element
+	codes   map[codeKey]ClientKey
+	pending map[codeKey]chan struct{}
+	lru     *list.List //Most recently used first
+}
+
+type registryEntry struct {
+	key      ClientKey
+	client   *Client
+	codes    []string
+	lastUsed time.Time
+}
+
+/*NewClientRegistry returns a registry which keeps up to maxSize clients, evicting the ones not used for ttl.*/
+func NewClientRegistry(maxSize int, ttl time.Duration) *ClientRegistry {
+	return &ClientRegistry{
+		maxSize: maxSize,
+		ttl:     ttl,
+		entries: make(map[ClientKey]*list.Element),
+		codes:   make(map[codeKey]ClientKey),
+		pending: make(map[codeKey]chan struct{}),
+		lru:     list.New(),
+	}
+}
+
+/*Get returns the client of the given user, if it is cached.*/
+func (registry *ClientRegistry) Get(clientID int64, userID int64) (*Client, bool) {
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	return registry.get(ClientKey{clientID, userID})
+}
+
+/*
+Add caches a client built by NewClientFromToken, NewClientFromRefreshToken or with DisableCache, so it is
+returned by MeliClient and refreshed by a BackgroundRefresher. The id of its user has to be known, either carried by its
+Authorization or resolved by Client.UserID. When the user already has a client, the cached one is kept, takes the
+Authorization of the given one and is returned. Logout evicts the client from the last registry it was added to.
+*/
+func (registry *ClientRegistry) Add(client *Client) (*Client, error) {
+
+	var userID int64
+	if !client.application {
+		if userID = client.knownUserID(); userID == 0 {
+			return nil, errors.New("the id of the user of the client is not known")
+		}
+	}
+
+	cached := registry.add(ClientKey{client.id, userID}, "", client)
+
+	cached.configMutex.Lock()
+	cached.registry = registry
+	cached.configMutex.Unlock()
+
+	return cached, nil
+}
+
+/*Remove evicts the client of the given user. The next call to MeliClient for this user builds a new one.*/
+func (registry *ClientRegistry) Remove(clientID int64, userID int64) {
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if element := registry.entries[ClientKey{clientID, userID}]; element != nil {
+		registry.remove(element)
+	}
+}
+
+/*Purge evicts every client.*/
+func (registry *ClientRegistry) Purge() {
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.entries = make(map[ClientKey]*list.Element)
+	registry.codes = make(map[codeKey]ClientKey)
+	registry.lru.Init()
+}
+
+/*Len returns the number of cached clients.*/
+func (registry *ClientRegistry) Len() int {
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.evictExpired(time.Now())
+	return registry.lru.Len()
+}
+
+/*clients returns the cached clients, without marking them as used.*/
+func (registry *ClientRegistry) clients() []*Client {
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	registry.evictExpired(time.Now())
+
+	clients := make([]*Client, 0, registry.lru.Len())
+	for element := registry.lru.Front(); element != nil; element = element.Next() {
+		clients = append(clients, element.Value.(*registryEntry).client)
+	}
+
+	return clients
+}
+
+/*
+clientForCode returns the cached client which was authorized with the given code. When there is none, it returns a done
+function instead, which the caller has to call once it finished exchanging the code, whether it succeeded or not.
+Since a code can only be exchanged once, concurrent calls for the same code wait until then, or until their ctx is done.
+*/
+func (registry *ClientRegistry) clientForCode(ctx context.Context, clientID int64, code string) (client *Client, done func(), err error) {
+
+	key := codeKey{clientID, code}
+
+	registry.mutex.Lock()
+
+	for {
+		if userKey, ok := registry.codes[key]; ok {
+			if client, ok := registry.get(userKey); ok {
+				registry.mutex.Unlock()
+				return client, nil, nil
+			}
+		}
+
+		wait := registry.pending[key]
+		if wait == nil {
+			break
+		}
+
+		registry.mutex.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+
+		registry.mutex.Lock()
+	}
+
+	wait := make(chan struct{})
+	registry.pending[key] = wait
+	registry.mutex.Unlock()
+
+	return nil, func() {
+		registry.mutex.Lock()
+		delete(registry.pending, key)
+		registry.mutex.Unlock()
+		close(wait)
+	}, nil
+}
+
+/*
+add caches client for the given user and returns the client which has to be used from now on.
+When the user already has a client, the cached one is kept and it takes the Authorization of the new one.
+The code the client was authorized with, if any, is remembered so it can be looked up later.
+*/
+func (registry *ClientRegistry) add(key ClientKey, code string, client *Client) *Client {
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	now := time.Now()
+	registry.evictExpired(now)
+
+	element := registry.entries[key]
+
+	if element != nil {
+		entry := element.Value.(*registryEntry)
+		if entry.client != client {
+			entry.client.setAuthorization(client.Authorization())
+		}
+		entry.lastUsed = now
+		registry.lru.MoveToFront(element)
+	} else {
+		element = registry.lru.PushFront(&registryEntry{key: key, client: client, lastUsed: now})
+		registry.entries[key] = element
+	}
+
+	entry := element.Value.(*registryEntry)
+
+	if code != "" {
+		registry.codes[codeKey{key.ClientID, code}] = key
+		entry.codes = append(entry.codes, code)
+	}
+
+	for registry.maxSize > 0 && registry.lru.Len() > registry.maxSize {
+		registry.remove(registry.lru.Back())
+	}
+
+	return entry.client
+}
+
+/*touch marks the given client as used, if it is still cached, so it is not evicted for being idle.*/
+func (registry *ClientRegistry) touch(client *Client) {
+
+	var userID int64
+	if !client.application {
+		userID = client.knownUserID()
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if element := registry.entries[ClientKey{client.id, userID}]; element != nil && element.Value.(*registryEntry).client == client {
+		element.Value.(*registryEntry).lastUsed = time.Now()
+		registry.lru.MoveToFront(element)
+	}
+}
+
+/*get returns the cached client and marks it as used. The mutex must be held.*/
+func (registry *ClientRegistry) get(key ClientKey) (*Client, bool) {
+
+	now := time.Now()
+	registry.evictExpired(now)
+
+	element := registry.entries[key]
+	if element == nil {
+		return nil, false
+	}
+
+	entry := element.Value.(*registryEntry)
+	entry.lastUsed = now
+	registry.lru.MoveToFront(element)
+
+	return entry.client, true
+}
+
+/*evictExpired removes the clients which were not used for TTL. The mutex must be held.*/
+func (registry *ClientRegistry) evictExpired(now time.Time) {
+
+	if registry.ttl <= 0 {
+		return
+	}
+
+	for element := registry.lru.Back(); element != nil; element = registry.lru.Back() {
+		if now.Sub(element.Value.(*registryEntry).lastUsed) < registry.ttl {
+			return
+		}
+		registry.remove(element)
+	}
+}
+
+func (registry *ClientRegistry) remove(element *list.Element) {
+
+	entry := registry.lru.Remove(element).(*registryEntry)
+	delete(registry.entries, entry.key)
+
+	for _, code := range entry.codes {
+		delete(registry.codes, codeKey{entry.key.ClientID, code})
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRegistryConfig(registry *ClientRegistry, code string) MeliConfig {
+	return MeliConfig{
+		ClientID:    CLIENT_ID,
+		UserCode:    code,
+		Secret:      CLIENT_SECRET,
+		CallBackURL: "http://www.example.com",
+		HTTPClient:  MockHttpClient{},
+		Registry:    registry,
+	}
+}
+
+func Test_the_same_client_is_returned_for_the_same_user(t *testing.T) {
+
+	registry := NewClientRegistry(10, 0)
+
+	client, err := MeliClient(newTestRegistryConfig(registry, USER_CODE))
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	//The code can not be exchanged twice, so the cached client has to be returned
+	again, err := MeliClient(newTestRegistryConfig(registry, USER_CODE))
+	if err != nil || again != client {
+		log.Printf("Error: the cached client should have been returned for the same code. error: %v\n", err)
+		t.FailNow()
+	}
+
+	//A new code of the same user gives the same client
+	other, err := MeliClient(newTestRegistryConfig(registry, "ANOTHER_CODE"))
+	if err != nil || other != client || registry.Len() != 1 {
+		log.Printf("Error: the cached client should have been returned for the same user. error: %v\n", err)
+		t.FailNow()
+	}
+
+	if cached, ok := registry.Get(CLIENT_ID, 214509008); !ok || cached != client {
+		log.Printf("Error: the client should be cached by application and user id\n")
+		t.FailNow()
+	}
+
+	registry.Remove(CLIENT_ID, 214509008)
+
+	if _, ok := registry.Get(CLIENT_ID, 214509008); ok || registry.Len() != 0 {
+		log.Printf("Error: the client should have been removed\n")
+		t.FailNow()
+	}
+
+	if again, _ := MeliClient(newTestRegistryConfig(registry, USER_CODE)); again == client {
+		log.Printf("Error: a new client should have been built after removing the cached one\n")
+		t.FailNow()
+	}
+}
+
+func Test_least_recently_used_clients_are_evicted(t *testing.T) {
+
+	registry := NewClientRegistry(2, 0)
+
+	first := registry.add(ClientKey{CLIENT_ID, 1}, "", newClient(MeliConfig{}))
+	registry.add(ClientKey{CLIENT_ID, 2}, "", newClient(MeliConfig{}))
+
+	//The first client becomes the most recently used one
+	registry.Get(CLIENT_ID, 1)
+	registry.add(ClientKey{CLIENT_ID, 3}, "", newClient(MeliConfig{}))
+
+	if _, ok := registry.Get(CLIENT_ID, 2); ok || registry.Len() != 2 {
+		log.Printf("Error: the least recently used client should have been evicted\n")
+		t.FailNow()
+	}
+
+	if cached, ok := registry.Get(CLIENT_ID, 1); !ok || cached != first {
+		log.Printf("Error: the most recently used client should have been kept\n")
+		t.FailNow()
+	}
+
+	registry.Purge()
+
+	if registry.Len() != 0 {
+		log.Printf("Error: every client should have been purged\n")
+		t.FailNow()
+	}
+}
+
+func Test_clients_not_used_for_the_ttl_are_evicted(t *testing.T) {
+
+	registry := NewClientRegistry(0, 20*time.Millisecond)
+
+	registry.add(ClientKey{CLIENT_ID, 1}, "code", newClient(MeliConfig{}))
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := registry.Get(CLIENT_ID, 1); ok {
+		log.Printf("Error: the client should have expired\n")
+		t.FailNow()
+	}
+
+	if client, done, err := registry.clientForCode(context.Background(), CLIENT_ID, "code"); client != nil || done == nil || err != nil {
+		log.Printf("Error: the code of an expired client should not be found\n")
+		t.FailNow()
+	} else {
+		done()
+	}
+}
+
+func Test_a_call_waiting_for_the_exchange_of_the_same_code_honors_its_context(t *testing.T) {
+
+	registry := NewClientRegistry(10, 0)
+
+	//The code is being exchanged by another call
+	_, done, _ := registry.clientForCode(context.Background(), CLIENT_ID, USER_CODE)
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	config := newTestRegistryConfig(registry, USER_CODE)
+
+	if _, err := MeliClientContext(ctx, config); !errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Error: the call should have stopped waiting once its context expired, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_a_changed_secret_and_http_client_are_applied_to_the_cached_client(t *testing.T) {
+
+	registry := NewClientRegistry(10, 0)
+
+	client, err := MeliClient(newTestRegistryConfig(registry, USER_CODE))
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	var calls int32
+	config := newTestRegistryConfig(registry, USER_CODE)
+	config.Secret = "new secret"
+	config.HTTPClient = HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return MockHttpClient{}.Do(req)
+	})
+
+	again, err := MeliClient(config)
+	if err != nil || again != client || client.getSecret() != "new secret" {
+		log.Printf("Error: the new secret should have been applied to the cached client. error: %v\n", err)
+		t.FailNow()
+	}
+
+	client.Get("/sites")
+
+	if atomic.LoadInt32(&calls) != 1 {
+		log.Printf("Error: the new http client should have been used\n")
+		t.FailNow()
+	}
+}
+
+func Test_clients_are_not_cached_when_the_cache_is_disabled(t *testing.T) {
+
+	registry := NewClientRegistry(10, 0)
+
+	config := newTestRegistryConfig(registry, USER_CODE)
+	config.DisableCache = true
+
+	if _, err := MeliClient(config); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if registry.Len() != 0 {
+		log.Printf("Error: the client should not have been cached\n")
+		t.FailNow()
+	}
+}
+
+func Test_clients_built_from_a_token_can_be_added(t *testing.T) {
+
+	registry := NewClientRegistry(10, 0)
+
+	config := newTestRegistryConfig(nil, "")
+	config.DisableCache = true
+
+	client, err := NewClientFromToken(config, Authorization{AccessToken: "valid token", RefreshToken: "valid refresh token", UserID: 1})
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if added, err := registry.Add(client); err != nil || added != client {
+		log.Printf("Error: the client should have been added %s\n", err)
+		t.FailNow()
+	}
+
+	if cached, ok := registry.Get(CLIENT_ID, 1); !ok || cached != client {
+		log.Printf("Error: the added client should be returned for its user\n")
+		t.FailNow()
+	}
+
+	if err := client.Logout(context.Background()); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if registry.Len() != 0 {
+		log.Printf("Error: the client should have been evicted by Logout\n")
+		t.FailNow()
+	}
+}
+
+func Test_clients_of_unknown_users_are_not_added(t *testing.T) {
+
+	config := newTestRegistryConfig(nil, "")
+	config.DisableCache = true
+
+	client, err := NewClientFromToken(config, Authorization{AccessToken: "valid token"})
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if _, err := NewClientRegistry(10, 0).Add(client); err == nil {
+		log.Printf("Error: a client whose user is not known should not be added\n")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const idempotencyKeyHeader = "X-Idempotency-Key"
+
+/*
+RetryPolicy tells the Client how to retry calls which failed because of transient errors,
+that is connection errors or any of the RetryableStatusCodes.
+
+Only idempotent calls (GET, HEAD, PUT, DELETE and OPTIONS) are retried. A POST is retried only when
+its context carries an idempotency key (see WithIdempotencyKey), so the API can detect duplicates.
+*/
+type RetryPolicy struct {
+	MaxAttempts          int           // Total number of attempts, including the first one
+	BaseDelay            time.Duration // Delay before the first retry, doubled on each attempt
+	MaxDelay             time.Duration // Upper bound for the delay between attempts
+	Jitter               float64       // Fraction (0 to 1) of each delay which is randomized
+	RetryableStatusCodes []int         // Status codes considered transient
+}
+
+/*DefaultRetryPolicy returns a policy which makes up to 3 attempts for the usual transient status codes.*/
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type idempotencyKeyContextKey struct{}
+
+/*
+WithIdempotencyKey returns a context whose calls are sent with the given idempotency key,
+which allows them to be retried even when they are not idempotent (i.e. POST).
+*/
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+/*IdempotencyKey returns the key set by WithIdempotencyKey, if any.*/
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+/*
+nextDelay reports whether the given attempt has to be retried and how long to wait before doing so.
+A nil policy never retries.
+*/
+func (policy *RetryPolicy) nextDelay(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+
+	if policy == nil || attempt >= policy.MaxAttempts || !policy.canRetry(req) {
+		return 0, false
+	}
+
+	if err != nil {
+		//Errors caused by the context being done are not transient
+		if req.Context().Err() != nil {
+			return 0, false
+		}
+		return policy.backoff(attempt), true
+	}
+
+	if !policy.isRetryable(resp.StatusCode) {
+		return 0, false
+	}
+
+	delay := policy.backoff(attempt)
+
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		if policy.MaxDelay > 0 && retryAfter > policy.MaxDelay {
+			return 0, false
+		}
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+	}
+
+	return delay, true
+}
+
+func (policy *RetryPolicy) canRetry(req *http.Request) bool {
+
+	//The body of the request has to be sent again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	return req.Header.Get(idempotencyKeyHeader) != ""
+}
+
+func (policy *RetryPolicy) isRetryable(statusCode int) bool {
+
+	for _, code := range policy.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+
+	delay := policy.BaseDelay << uint(attempt-1)
+
+	if delay <= 0 || (policy.MaxDelay > 0 && delay > policy.MaxDelay) {
+		delay = policy.MaxDelay
+	}
+
+	if policy.Jitter > 0 {
+		delay -= time.Duration(policy.Jitter * rand.Float64() * float64(delay))
+	}
+
+	return delay
+}
+
+/*The Retry-After header holds either a number of seconds or an HTTP date.*/
+func parseRetryAfter(value string) (time.Duration, bool) {
+
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+/*discardResponse drains and closes the body of a response which is not going to be returned, so its connection can be reused.*/
+func discardResponse(resp *http.Response) {
+
+	if resp == nil || resp.Body == nil {
+		return
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 10 * time.Millisecond
+	return policy
+}
+
+/*newFlakyServer returns a server which answers 503 to the given number of calls before answering 200.*/
+func newFlakyServer(failures int32, calls *int32, idempotencyKey *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if idempotencyKey != nil {
+			*idempotencyKey = r.Header.Get("X-Idempotency-Key")
+		}
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func Test_GET_is_retried_when_a_transient_status_code_is_received(t *testing.T) {
+
+	var calls int32
+	server := newFlakyServer(2, &calls, nil)
+	defer server.Close()
+
+	client := newClient(MeliConfig{HTTPClient: MeliHTTPClient{Client: server.Client()}, RetryPolicy: newTestRetryPolicy()})
+	client.apiURL = server.URL
+
+	resp, err := client.Get("/sites")
+
+	if err != nil || resp.StatusCode != http.StatusOK || calls != 3 {
+		log.Printf("Error: GET should have succeeded after 3 attempts. calls: %d error: %v\n", calls, err)
+		t.FailNow()
+	}
+}
+
+func Test_an_APIError_is_returned_when_attempts_are_exhausted(t *testing.T) {
+
+	var calls int32
+	server := newFlakyServer(5, &calls, nil)
+	defer server.Close()
+
+	client := newClient(MeliConfig{HTTPClient: MeliHTTPClient{Client: server.Client()}, RetryPolicy: newTestRetryPolicy()})
+	client.apiURL = server.URL
+
+	_, err := client.Get("/sites")
+
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusServiceUnavailable || calls != 3 {
+		log.Printf("Error: a 503 APIError was expected after 3 attempts. calls: %d error: %v\n", calls, err)
+		t.FailNow()
+	}
+}
+
+func Test_POST_is_only_retried_when_an_idempotency_key_is_given(t *testing.T) {
+
+	var calls int32
+	var idempotencyKey string
+	server := newFlakyServer(1, &calls, &idempotencyKey)
+	defer server.Close()
+
+	client := newClient(MeliConfig{HTTPClient: MeliHTTPClient{Client: server.Client()}, RetryPolicy: newTestRetryPolicy()})
+	client.apiURL = server.URL
+
+	if _, err := client.Post("/items", "{\"foo\":\"bar\"}"); err == nil || calls != 1 {
+		log.Printf("Error: POST without idempotency key should not have been retried. calls: %d\n", calls)
+		t.FailNow()
+	}
+
+	calls = 0
+	ctx := WithIdempotencyKey(context.Background(), "item-1")
+
+	if _, err := client.PostContext(ctx, "/items", "{\"foo\":\"bar\"}"); err != nil || calls != 2 || idempotencyKey != "item-1" {
+		log.Printf("Error: POST with idempotency key should have been retried. calls: %d key: %s error: %v\n", calls, idempotencyKey, err)
+		t.FailNow()
+	}
+}
+
+func Test_Retry_After_longer_than_MaxDelay_is_not_retried(t *testing.T) {
+
+	policy := newTestRetryPolicy()
+	req, _ := http.NewRequest(http.MethodGet, APIURL+"/sites", nil)
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "1")
+
+	if _, retry := policy.nextDelay(1, req, resp, nil); retry {
+		log.Printf("Error: a Retry-After longer than MaxDelay should not be retried\n")
+		t.FailNow()
+	}
+
+	resp.Header.Set("Retry-After", "0")
+
+	if _, retry := policy.nextDelay(1, req, resp, nil); !retry {
+		log.Printf("Error: a Retry-After shorter than MaxDelay should be retried\n")
+		t.FailNow()
+	}
+}
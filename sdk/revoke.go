@@ -0,0 +1,140 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+/*
+Revoke revokes the grant the user gave to the application, so its tokens can not be used anymore,
+and then forgets the user as Logout does. A RevokedError is returned if the grant had already been revoked;
+the user is forgotten anyway.
+*/
+func (client *Client) Revoke(ctx context.Context) error {
+
+	if !client.IsAuthorized() || client.application {
+		return errors.New("the client is not authorized by any user")
+	}
+
+	userID, err := client.UserID(ctx)
+	if err == nil {
+		err = client.revoke(ctx, userID)
+	}
+
+	if err != nil {
+		if !isRevoked(err) {
+			return err
+		}
+		err = &RevokedError{UserID: userID, Err: err}
+	}
+
+	if forgetErr := client.forget(ctx, userID); forgetErr != nil && err == nil {
+		return forgetErr
+	}
+
+	return err
+}
+
+func (client *Client) revoke(ctx context.Context, userID int64) error {
+
+	resp, err := client.DeleteContext(ctx, "/users/"+strconv.FormatInt(userID, 10)+"/applications/"+strconv.FormatInt(client.id, 10))
+	if err != nil {
+		return err
+	}
+
+	discardResponse(resp)
+	return nil
+}
+
+/*
+Logout forgets the user without revoking its grant: the Authorization of the client is cleared,
+and the client is evicted from its ClientRegistry and its token removed from the TokenStore.
+The client can only be used to access the public API afterwards. A token refresh in progress is waited for, so it can
+not bring the tokens back; ctx bounds that wait.
+*/
+func (client *Client) Logout(ctx context.Context) error {
+
+	if client.application {
+		if err := client.clearAuthorization(ctx); err != nil {
+			return err
+		}
+		if registry := client.getRegistry(); registry != nil {
+			registry.Remove(client.id, 0)
+		}
+		return nil
+	}
+
+	return client.forget(ctx, client.knownUserID())
+}
+
+func (client *Client) forget(ctx context.Context, userID int64) error {
+
+	if err := client.clearAuthorization(ctx); err != nil {
+		return err
+	}
+
+	if userID == 0 {
+		return nil
+	}
+
+	if registry := client.getRegistry(); registry != nil {
+		registry.Remove(client.id, userID)
+	}
+
+	if client.tokenStore != nil {
+		if err := client.tokenStore.Delete(ctx, client.id, userID); err != nil && !errors.Is(err, ErrTokenNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+clearAuthorization clears the Authorization of the client once the refresh in progress, if any, is over.
+Otherwise the refresh would restore the tokens, and store them again, after they were cleared.
+*/
+func (client *Client) clearAuthorization(ctx context.Context) error {
+
+	for {
+		client.refreshMutex.Lock()
+
+		call := client.refreshing
+		if call == nil {
+			//No refresh can start while the lock is held
+			client.setAuthorization(anonymous)
+			client.refreshMutex.Unlock()
+			return nil
+		}
+
+		client.refreshMutex.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+/*isRevoked reports whether err means the token of the user is not valid anymore.*/
+func isRevoked(err error) bool {
+	return IsUnauthorized(err) || IsInvalidGrant(err) || IsNotFound(err) || errors.Is(err, ErrReauthorizationRequired)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*newRevocationTestClient returns an authorized client, cached and stored, whose revocation is answered with the given status.*/
+func newRevocationTestClient(status int, revoked *string) (*Client, *ClientRegistry, TokenStore) {
+
+	registry := NewClientRegistry(10, 0)
+	store := NewMemoryTokenStore()
+
+	config := MeliConfig{
+		ClientID:    CLIENT_ID,
+		UserCode:    USER_CODE,
+		Secret:      CLIENT_SECRET,
+		CallBackURL: "http://www.example.com",
+		Registry:    registry,
+		TokenStore:  store,
+		HTTPClient: HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodDelete {
+				return MockHttpClient{}.Do(req)
+			}
+			*revoked = req.URL.Path
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil
+		}),
+	}
+
+	client, _ := MeliClient(config)
+
+	return client, registry, store
+}
+
+func Test_Revoke_deletes_the_grant_and_forgets_the_user(t *testing.T) {
+
+	var revoked string
+	client, registry, store := newRevocationTestClient(http.StatusOK, &revoked)
+
+	if err := client.Revoke(context.Background()); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if revoked != "/users/214509008/applications/123456" {
+		log.Printf("Error: unexpected revocation endpoint %s\n", revoked)
+		t.FailNow()
+	}
+
+	if client.IsAuthorized() || registry.Len() != 0 {
+		log.Printf("Error: the client should have been cleared and evicted\n")
+		t.FailNow()
+	}
+
+	if _, err := store.Load(context.Background(), CLIENT_ID, 214509008); !errors.Is(err, ErrTokenNotFound) {
+		log.Printf("Error: the token should have been deleted, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_Revoke_returns_a_RevokedError_when_the_grant_was_already_revoked(t *testing.T) {
+
+	var revoked string
+	client, registry, _ := newRevocationTestClient(http.StatusUnauthorized, &revoked)
+
+	err := client.Revoke(context.Background())
+
+	var revokedErr *RevokedError
+	if !errors.Is(err, ErrGrantRevoked) || !errors.As(err, &revokedErr) || revokedErr.UserID != 214509008 || !IsUnauthorized(err) {
+		log.Printf("Error: a RevokedError was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if client.IsAuthorized() || registry.Len() != 0 {
+		log.Printf("Error: the user should have been forgotten anyway\n")
+		t.FailNow()
+	}
+}
+
+func Test_Logout_forgets_the_user_without_revoking_the_grant(t *testing.T) {
+
+	var revoked string
+	client, registry, store := newRevocationTestClient(http.StatusOK, &revoked)
+
+	if err := client.Logout(context.Background()); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if revoked != "" || client.IsAuthorized() || registry.Len() != 0 {
+		log.Printf("Error: the user should have been forgotten without revoking the grant\n")
+		t.FailNow()
+	}
+
+	if _, err := store.Load(context.Background(), CLIENT_ID, 214509008); !errors.Is(err, ErrTokenNotFound) {
+		log.Printf("Error: the token should have been deleted, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_Logout_waits_for_the_refresh_in_progress(t *testing.T) {
+
+	var revoked string
+	client, registry, store := newRevocationTestClient(http.StatusOK, &revoked)
+
+	refresher := &MockCountingTokenRefresher{release: make(chan struct{})}
+	client.tokenRefresher = refresher
+
+	refreshed := make(chan error)
+	go func() {
+		refreshed <- client.refreshTokenOnce(context.Background(), func(Authorization) bool { return true })
+	}()
+
+	for atomic.LoadInt32(&refresher.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	loggedOut := make(chan error)
+	go func() {
+		loggedOut <- client.Logout(context.Background())
+	}()
+
+	select {
+	case err := <-loggedOut:
+		log.Printf("Error: Logout should have waited for the refresh, it returned %v\n", err)
+		t.FailNow()
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(refresher.release)
+
+	if err := <-refreshed; err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if err := <-loggedOut; err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if client.IsAuthorized() || registry.Len() != 0 {
+		log.Printf("Error: the refreshed token should not have been restored\n")
+		t.FailNow()
+	}
+
+	if _, err := store.Load(context.Background(), CLIENT_ID, 214509008); !errors.Is(err, ErrTokenNotFound) {
+		log.Printf("Error: the refreshed token should not have been stored again, obtained %v\n", err)
+		t.FailNow()
+	}
+}
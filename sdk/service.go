@@ -0,0 +1,98 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+/*
+This file holds the plumbing shared by the typed services (Items, Orders...), which send and receive JSON
+through the same request pipeline as Get, Post, Put and Delete.
+*/
+
+/*Paging describes the page of the results returned by a search.*/
+type Paging struct {
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+/*getJSON performs a GET of resource and decodes the response into out.*/
+func (client *Client) getJSON(ctx context.Context, resource string, out interface{}) error {
+	return client.doJSON(ctx, http.MethodGet, resource, nil, out)
+}
+
+/*
+doJSON performs a call to resource, sending in encoded as JSON unless it is nil, and decoding the response into out
+unless it is nil. Errors are the same returned by Get, Post, Put and Delete, i.e. *APIError for non 2xx responses.
+Methods other than GET, POST, PUT, PATCH, DELETE and HEAD fail without calling the API.
+*/
+func (client *Client) doJSON(ctx context.Context, method string, resource string, in interface{}, out interface{}) error {
+
+	var body string
+
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = string(encoded)
+	}
+
+	var callback Callback
+
+	switch method {
+	case http.MethodGet:
+		callback = HTTPGet{}
+	case http.MethodPost:
+		callback = HTTPPost{body: body}
+	case http.MethodPut:
+		callback = HTTPPut{body: body}
+	case http.MethodPatch:
+		callback = HTTPPatch{body: body}
+	case http.MethodDelete:
+		callback = HTTPDelete{}
+	case http.MethodHead:
+		callback = HTTPHead{}
+	default:
+		return fmt.Errorf("%s requests are not supported", method)
+	}
+
+	resp, err := httpErrorHandler(ctx, client, resource, callback)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		discardResponse(resp)
+		return nil
+	}
+
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+/*pathID escapes an id given by the caller, so it can be used as a segment of a resource path.*/
+func pathID(id string) string {
+	return url.PathEscape(id)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/*newServiceTestClient returns a client authorized by TEST_USER_ID whose calls to the API are answered by handler.*/
+func newServiceTestClient(handler http.HandlerFunc) (*Client, func()) {
+
+	server := httptest.NewServer(handler)
+
+	client := newClient(MeliConfig{ClientID: CLIENT_ID, Secret: CLIENT_SECRET, HTTPClient: MeliHTTPClient{Client: server.Client()}, DisableCache: true})
+	client.apiURL = server.URL
+
+	auth := Authorization{AccessToken: "valid token", ExpiresIn: 6 * time.Hour, RefreshToken: "valid refresh token", UserID: 214509008}
+	auth.received(time.Now())
+	client.setAuthorization(auth)
+
+	return client, server.Close
+}
+
+func Test_doJSON_sends_and_decodes_json(t *testing.T) {
+
+	var method, body string
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		received, _ := ioutil.ReadAll(r.Body)
+		body = string(received)
+		w.Write([]byte(`{"id":"MLA123"}`))
+	})
+	defer closeServer()
+
+	var out struct {
+		ID string `json:"id"`
+	}
+
+	in := map[string]string{"title": "a title"}
+	if err := client.doJSON(context.Background(), http.MethodPatch, "/items/MLA123", in, &out); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	if method != http.MethodPatch || body != `{"title":"a title"}` || out.ID != "MLA123" {
+		log.Printf("Error: unexpected call %s %s or response %+v\n", method, body, out)
+		t.FailNow()
+	}
+}
+
+func Test_doJSON_returns_an_APIError_when_the_call_fails(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"forbidden","error":"forbidden","status":403}`))
+	})
+	defer closeServer()
+
+	var apiErr *APIError
+	err := client.getJSON(context.Background(), "/items/MLA123", &struct{}{})
+
+	if !errors.As(err, &apiErr) || !IsForbidden(err) || apiErr.Endpoint != "/items/MLA123" {
+		log.Printf("Error: an APIError was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_doJSON_rejects_unsupported_methods(t *testing.T) {
+
+	called := false
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	defer closeServer()
+
+	if err := client.doJSON(context.Background(), http.MethodOptions, "/items/MLA123", nil, nil); err == nil || called {
+		log.Printf("Error: an unsupported method should fail without calling the API, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_pathID_escapes_the_id(t *testing.T) {
+
+	if pathID("MLA1/../users") != "MLA1%2F..%2Fusers" {
+		log.Printf("Error: unexpected escaped id %s\n", pathID("MLA1/../users"))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*Status of the shipments.*/
+const (
+	ShipmentPending      = "pending"
+	ShipmentHandling     = "handling"
+	ShipmentReadyToShip  = "ready_to_ship"
+	ShipmentShipped      = "shipped"
+	ShipmentDelivered    = "delivered"
+	ShipmentNotDelivered = "not_delivered"
+	ShipmentCancelled    = "cancelled"
+)
+
+/*Formats of the shipping labels.*/
+const (
+	LabelPDF = "pdf"
+	LabelZPL = "zpl2" // Zebra printers. Sent as a zip file holding the labels
+)
+
+/*Max number of shipments whose labels can be downloaded at once.*/
+const maxLabelShipments = 50
+
+/*Shipment is the delivery of an order.*/
+type Shipment struct {
+	ID              int64            `json:"id"`
+	Mode            string           `json:"mode"`
+	Status          string           `json:"status"`
+	Substatus       string           `json:"substatus"`
+	OrderID         int64            `json:"order_id"`
+	SenderID        int64            `json:"sender_id"`
+	ReceiverID      int64            `json:"receiver_id"`
+	TrackingNumber  string           `json:"tracking_number"`
+	TrackingMethod  string           `json:"tracking_method"`
+	LogisticType    string           `json:"logistic_type"`
+	DateCreated     *time.Time       `json:"date_created"`
+	LastUpdated     *time.Time       `json:"last_updated"`
+	ShippingOption  *ShippingOption  `json:"shipping_option"`
+	ReceiverAddress *ShipmentAddress `json:"receiver_address"`
+	StatusHistory   struct {
+		DateHandling    *time.Time `json:"date_handling"`
+		DateReadyToShip *time.Time `json:"date_ready_to_ship"`
+		DateShipped     *time.Time `json:"date_shipped"`
+		DateDelivered   *time.Time `json:"date_delivered"`
+		DateCancelled   *time.Time `json:"date_cancelled"`
+	} `json:"status_history"`
+}
+
+/*ShippingOption is the shipping method chosen by the buyer, along with its cost.*/
+type ShippingOption struct {
+	ID                    int64                  `json:"id"`
+	Name                  string                 `json:"name"`
+	ShippingMethodID      int64                  `json:"shipping_method_id"`
+	Cost                  float64                `json:"cost"`
+	ListCost              float64                `json:"list_cost"`
+	CurrencyID            string                 `json:"currency_id"`
+	EstimatedDeliveryTime *EstimatedDeliveryTime `json:"estimated_delivery_time"`
+}
+
+/*EstimatedDeliveryTime tells when a shipment is expected to be delivered.*/
+type EstimatedDeliveryTime struct {
+	Type     string     `json:"type"`
+	Date     *time.Time `json:"date"`
+	Unit     string     `json:"unit"`
+	Shipping int        `json:"shipping"` // Time it takes once shipped, in Unit
+	Handling int        `json:"handling"` // Time the seller has to ship it, in Unit
+}
+
+/*ShipmentAddress is the address a shipment is delivered to.*/
+type ShipmentAddress struct {
+	AddressLine  string `json:"address_line"`
+	StreetName   string `json:"street_name"`
+	StreetNumber string `json:"street_number"`
+	ZipCode      string `json:"zip_code"`
+	City         struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"city"`
+	State struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"state"`
+	Comment      string `json:"comment"`
+	ReceiverName string `json:"receiver_name"`
+}
+
+/*ShipmentStatus is one of the statuses a shipment went through.*/
+type ShipmentStatus struct {
+	Status    string     `json:"status"`
+	Substatus string     `json:"substatus"`
+	Date      *time.Time `json:"date"`
+}
+
+/*LeadTime is the estimated time a shipment takes, and its cost.*/
+type LeadTime struct {
+	OptionID       int64   `json:"option_id"`
+	Cost           float64 `json:"cost"`
+	ListCost       float64 `json:"list_cost"`
+	CurrencyID     string  `json:"currency_id"`
+	ShippingMethod struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"shipping_method"`
+	EstimatedDeliveryTime *EstimatedDeliveryTime `json:"estimated_delivery_time"`
+}
+
+/*ShipmentsService gives typed access to the /shipments resources.*/
+type ShipmentsService struct {
+	client *Client
+}
+
+/*Shipments returns the service to retrieve the shipments of the user of the client.*/
+func (client *Client) Shipments() *ShipmentsService {
+	return &ShipmentsService{client: client}
+}
+
+/*Get retrieves the shipment with the given id.*/
+func (service *ShipmentsService) Get(ctx context.Context, id int64) (*Shipment, error) {
+
+	shipment := new(Shipment)
+	if err := service.client.getJSON(ctx, "/shipments/"+strconv.FormatInt(id, 10), shipment); err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+/*History retrieves the statuses the shipment went through, the oldest first.*/
+func (service *ShipmentsService) History(ctx context.Context, id int64) ([]ShipmentStatus, error) {
+
+	var history []ShipmentStatus
+	if err := service.client.getJSON(ctx, "/shipments/"+strconv.FormatInt(id, 10)+"/history", &history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+/*LeadTime retrieves the estimated delivery time of the shipment.*/
+func (service *ShipmentsService) LeadTime(ctx context.Context, id int64) (*LeadTime, error) {
+
+	leadTime := new(LeadTime)
+	if err := service.client.getJSON(ctx, "/shipments/"+strconv.FormatInt(id, 10)+"/lead_time", leadTime); err != nil {
+		return nil, err
+	}
+
+	return leadTime, nil
+}
+
+/*
+DownloadLabels writes to w the printable labels of the given shipments, up to 50 at once, in the given format
+(LabelPDF, the default, or LabelZPL). The labels are streamed as they are received; the number of bytes written is returned.
+The shipments have to be ready to ship.
+*/
+func (service *ShipmentsService) DownloadLabels(ctx context.Context, w io.Writer, format string, ids ...int64) (int64, error) {
+
+	if len(ids) == 0 {
+		return 0, errors.New("no shipment ids were given")
+	}
+
+	if len(ids) > maxLabelShipments {
+		return 0, errors.New("up to " + strconv.Itoa(maxLabelShipments) + " labels can be downloaded at once")
+	}
+
+	if format == "" {
+		format = LabelPDF
+	}
+
+	shipmentIDs := make([]string, len(ids))
+	for i, id := range ids {
+		shipmentIDs[i] = strconv.FormatInt(id, 10)
+	}
+
+	query := url.Values{}
+	query.Set("shipment_ids", strings.Join(shipmentIDs, ","))
+	query.Set("response_type", format)
+
+	resp, err := service.client.GetContext(ctx, "/shipment_labels?"+query.Encode())
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return io.Copy(w, resp.Body)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"testing"
+)
+
+func Test_Shipments_Get_History_and_LeadTime_return_typed_values(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/shipments/4000":
+			w.Write([]byte(`{"id":4000,"mode":"me2","status":"shipped","order_id":2000003508,"tracking_number":"TN123",
+				"shipping_option":{"id":1,"name":"Normal","cost":100,"currency_id":"ARS","estimated_delivery_time":{"date":"2016-03-05T00:00:00.000-03:00"}},
+				"receiver_address":{"street_name":"Corrientes","street_number":"1234","city":{"name":"Buenos Aires"}},
+				"status_history":{"date_shipped":"2016-03-02T10:00:00.000-03:00"}}`))
+		case "/shipments/4000/history":
+			w.Write([]byte(`[{"status":"ready_to_ship","substatus":"printed","date":"2016-03-01T10:00:00.000-03:00"},{"status":"shipped","date":"2016-03-02T10:00:00.000-03:00"}]`))
+		case "/shipments/4000/lead_time":
+			w.Write([]byte(`{"option_id":1,"cost":100,"currency_id":"ARS","shipping_method":{"id":73328,"name":"Normal"},"estimated_delivery_time":{"type":"known","shipping":48,"unit":"hour"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer closeServer()
+
+	shipments := client.Shipments()
+	ctx := context.Background()
+
+	shipment, err := shipments.Get(ctx, 4000)
+	if err != nil || shipment.Status != ShipmentShipped || shipment.ShippingOption.Cost != 100 ||
+		shipment.ShippingOption.EstimatedDeliveryTime.Date == nil || shipment.ReceiverAddress.City.Name != "Buenos Aires" ||
+		shipment.StatusHistory.DateShipped == nil {
+		log.Printf("Error: unexpected shipment %+v %v\n", shipment, err)
+		t.FailNow()
+	}
+
+	history, err := shipments.History(ctx, 4000)
+	if err != nil || len(history) != 2 || history[0].Substatus != "printed" || history[1].Status != ShipmentShipped {
+		log.Printf("Error: unexpected history %+v %v\n", history, err)
+		t.FailNow()
+	}
+
+	leadTime, err := shipments.LeadTime(ctx, 4000)
+	if err != nil || leadTime.ShippingMethod.ID != 73328 || leadTime.EstimatedDeliveryTime.Shipping != 48 {
+		log.Printf("Error: unexpected lead time %+v %v\n", leadTime, err)
+		t.FailNow()
+	}
+}
+
+func Test_Shipments_DownloadLabels_streams_the_labels(t *testing.T) {
+
+	var query string
+	label := []byte("%PDF-1.4 label")
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(label)
+	})
+	defer closeServer()
+
+	var out bytes.Buffer
+	written, err := client.Shipments().DownloadLabels(context.Background(), &out, "", 4000, 4001)
+
+	if err != nil || written != int64(len(label)) || !bytes.Equal(out.Bytes(), label) {
+		log.Printf("Error: unexpected label of %d bytes %v\n", written, err)
+		t.FailNow()
+	}
+
+	if query != "response_type=pdf&shipment_ids=4000%2C4001" {
+		log.Printf("Error: unexpected query %s\n", query)
+		t.FailNow()
+	}
+
+	if _, err := client.Shipments().DownloadLabels(context.Background(), &out, LabelZPL); err == nil {
+		log.Printf("Error: an error was expected when no shipment ids are given\n")
+		t.FailNow()
+	}
+}
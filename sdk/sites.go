@@ -0,0 +1,138 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*ErrUnknownSite is returned when a site id is not one of the known Sites.*/
+var ErrUnknownSite = errors.New("unknown site")
+
+/*Site is one of the mercadolibre marketplaces, i.e. MLA for Argentina.*/
+type Site struct {
+	ID       string // i.e. MLA
+	Name     string
+	Country  string // ISO 3166-1 alpha-2 code, i.e. AR. Empty for the sites which are not bound to a country
+	AuthURL  string // Base URL of the authorization page, i.e. AuthURLMLA
+	Currency string // Default currency, i.e. ARS
+	Locale   string // i.e. es_AR
+	Quirks   SiteQuirks
+}
+
+/*SiteQuirks are the differences in the behavior of the API among sites.*/
+type SiteQuirks struct {
+	ExtraCurrencies []string // Currencies accepted by the listings besides the default one, i.e. USD in Uruguay
+	CrossBorder     bool     // Global selling site, whose items are published in other sites
+}
+
+var sites = []Site{
+	{ID: "MLA", Name: "Argentina", Country: "AR", AuthURL: AuthURLMLA, Currency: "ARS", Locale: "es_AR", Quirks: SiteQuirks{ExtraCurrencies: []string{"USD"}}},
+	{ID: "MLB", Name: "Brasil", Country: "BR", AuthURL: AuthURLMLB, Currency: "BRL", Locale: "pt_BR"},
+	{ID: "MCO", Name: "Colombia", Country: "CO", AuthURL: AuthURLMco, Currency: "COP", Locale: "es_CO"},
+	{ID: "MCR", Name: "Costa Rica", Country: "CR", AuthURL: AuthURLMcr, Currency: "CRC", Locale: "es_CR", Quirks: SiteQuirks{ExtraCurrencies: []string{"USD"}}},
+	{ID: "MEC", Name: "Ecuador", Country: "EC", AuthURL: AuthURLMec, Currency: "USD", Locale: "es_EC"},
+	{ID: "MLC", Name: "Chile", Country: "CL", AuthURL: AuthURLMlc, Currency: "CLP", Locale: "es_CL", Quirks: SiteQuirks{ExtraCurrencies: []string{"CLF"}}},
+	{ID: "MLM", Name: "Mexico", Country: "MX", AuthURL: AuthURLMLM, Currency: "MXN", Locale: "es_MX", Quirks: SiteQuirks{ExtraCurrencies: []string{"USD"}}},
+	{ID: "MLU", Name: "Uruguay", Country: "UY", AuthURL: AuthURLMlu, Currency: "UYU", Locale: "es_UY", Quirks: SiteQuirks{ExtraCurrencies: []string{"USD"}}},
+	{ID: "MLV", Name: "Venezuela", Country: "VE", AuthURL: AuthURLMlv, Currency: "VES", Locale: "es_VE", Quirks: SiteQuirks{ExtraCurrencies: []string{"USD"}}},
+	{ID: "MPA", Name: "Panama", Country: "PA", AuthURL: AuthURLMpa, Currency: "USD", Locale: "es_PA"},
+	{ID: "MPE", Name: "Peru", Country: "PE", AuthURL: AuthURLMpe, Currency: "PEN", Locale: "es_PE", Quirks: SiteQuirks{ExtraCurrencies: []string{"USD"}}},
+	{ID: "MPT", Name: "Portugal", Country: "PT", AuthURL: AuthURLMpt, Currency: "EUR", Locale: "pt_PT"},
+	{ID: "MRD", Name: "Dominicana", Country: "DO", AuthURL: AuthURLMrd, Currency: "DOP", Locale: "es_DO", Quirks: SiteQuirks{ExtraCurrencies: []string{"USD"}}},
+	{ID: "CBT", Name: "Global Selling", AuthURL: AuthURlCBT, Currency: "USD", Locale: "en_US", Quirks: SiteQuirks{CrossBorder: true}},
+}
+
+/*Sites returns every known site.*/
+func Sites() []Site {
+
+	result := make([]Site, len(sites))
+	copy(result, sites)
+
+	return result
+}
+
+/*SiteByID returns the site with the given id, i.e. MLA. The id is not case sensitive.*/
+func SiteByID(id string) (Site, bool) {
+
+	for _, site := range sites {
+		if strings.EqualFold(site.ID, id) {
+			return site, true
+		}
+	}
+
+	return Site{}, false
+}
+
+/*SiteByCountry returns the site of the given country, by its ISO 3166-1 alpha-2 code, i.e. AR. The code is not case sensitive.*/
+func SiteByCountry(country string) (Site, bool) {
+
+	for _, site := range sites {
+		if site.Country != "" && strings.EqualFold(site.Country, country) {
+			return site, true
+		}
+	}
+
+	return Site{}, false
+}
+
+/*AcceptsCurrency reports whether listings of the site can be priced in the given currency.*/
+func (site Site) AcceptsCurrency(currency string) bool {
+
+	if strings.EqualFold(site.Currency, currency) {
+		return true
+	}
+
+	for _, extra := range site.Quirks.ExtraCurrencies {
+		if strings.EqualFold(extra, currency) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*knownSite returns the site with the given id, or an error wrapping ErrUnknownSite if there is none.*/
+func knownSite(id string) (Site, error) {
+
+	site, ok := SiteByID(id)
+	if !ok {
+		return Site{}, fmt.Errorf("%w: %q", ErrUnknownSite, id)
+	}
+
+	return site, nil
+}
+
+/*
+authBaseURL returns the base URL of the authorization page for baseSite, which is either
+a site id, i.e. MLA, or the base URL itself, i.e. AuthURLMLA. Anything else is rejected with an error wrapping ErrUnknownSite.
+*/
+func authBaseURL(baseSite string) (string, error) {
+
+	if site, ok := SiteByID(baseSite); ok {
+		return site.AuthURL, nil
+	}
+
+	if base, err := url.Parse(baseSite); err == nil && (base.Scheme == "https" || base.Scheme == "http") && base.Host != "" {
+		return strings.TrimSuffix(baseSite, "/"), nil
+	}
+
+	return "", fmt.Errorf("%w: %q is neither a site id nor the URL of an authorization page", ErrUnknownSite, baseSite)
+}
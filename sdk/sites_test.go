@@ -0,0 +1,88 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func Test_sites_are_found_by_id_and_by_country(t *testing.T) {
+
+	site, ok := SiteByID("mlb")
+
+	if !ok || site.Country != "BR" || site.Currency != "BRL" || site.AuthURL != AuthURLMLB {
+		log.Printf("Error: MLB was expected, obtained %+v\n", site)
+		t.FailNow()
+	}
+
+	if byCountry, ok := SiteByCountry("br"); !ok || byCountry.ID != "MLB" {
+		log.Printf("Error: MLB was expected for BR, obtained %+v\n", byCountry)
+		t.FailNow()
+	}
+
+	if _, ok := SiteByID("XXX"); ok {
+		log.Printf("Error: an unknown site should not be found\n")
+		t.FailNow()
+	}
+
+	//The global selling site is not bound to a country
+	if _, ok := SiteByCountry(""); ok {
+		log.Printf("Error: no site should be found for an empty country\n")
+		t.FailNow()
+	}
+}
+
+func Test_sites_accept_their_extra_currencies(t *testing.T) {
+
+	uruguay, _ := SiteByID("MLU")
+	brasil, _ := SiteByID("MLB")
+
+	if !uruguay.AcceptsCurrency("UYU") || !uruguay.AcceptsCurrency("usd") || brasil.AcceptsCurrency("USD") {
+		log.Printf("Error: unexpected accepted currencies\n")
+		t.FailNow()
+	}
+}
+
+func Test_auth_URL_is_built_from_a_site_id(t *testing.T) {
+
+	if GetAuthURL(CLIENT_ID, "MLA", "http://someurl.com") != GetAuthURL(CLIENT_ID, AuthURLMLA, "http://someurl.com") {
+		log.Printf("Error: the site id should have been resolved to its authorization URL\n")
+		t.FailNow()
+	}
+}
+
+func Test_auth_URL_is_not_built_from_an_unknown_site(t *testing.T) {
+
+	if authURL := GetAuthURL(CLIENT_ID, "MLX", "http://someurl.com"); authURL != "" {
+		log.Printf("Error: no URL should have been built for an unknown site, obtained %s\n", authURL)
+		t.FailNow()
+	}
+
+	if _, err := (AuthURLBuilder{ClientID: CLIENT_ID, BaseSite: "MLX", CallBackURL: "http://someurl.com"}).Build(); !errors.Is(err, ErrUnknownSite) {
+		log.Printf("Error: ErrUnknownSite was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	if request, err := (AuthURLBuilder{ClientID: CLIENT_ID, BaseSite: "https://auth.example.com/", CallBackURL: "http://someurl.com"}).Build(); err != nil ||
+		!strings.HasPrefix(request.URL, "https://auth.example.com/authorization?") {
+		log.Printf("Error: the base URL given should have been used %v\n", err)
+		t.FailNow()
+	}
+}
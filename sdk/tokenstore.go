@@ -0,0 +1,242 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*ErrTokenNotFound is returned by the TokenStore implementations when there is no Authorization for the given user.*/
+var ErrTokenNotFound = errors.New("token not found")
+
+/*
+ErrTokenNotSaved wraps the error of a TokenStore which could not save a token the client obtained. The token is usable
+nonetheless: the authorized client is returned along with it, and the calls which refreshed the token go on.
+The token can be saved again from Client.Authorization.
+*/
+var ErrTokenNotSaved = errors.New("the token could not be saved")
+
+/*
+TokenStore allows persisting the Authorization of each user, so clients can be built again after a restart
+without asking the user to authorize the application again.
+The Authorization is saved every time it is obtained or refreshed, since mercadolibre rotates the refresh tokens.
+*/
+type TokenStore interface {
+	Load(ctx context.Context, clientID int64, userID int64) (*Authorization, error)
+	Save(ctx context.Context, clientID int64, userID int64, auth Authorization) error
+	Delete(ctx context.Context, clientID int64, userID int64) error
+}
+
+type tokenKey struct {
+	clientID int64
+	userID   int64
+}
+
+/*MemoryTokenStore keeps the tokens in memory. It is mostly useful for testing.*/
+type MemoryTokenStore struct {
+	mutex  sync.RWMutex
+	tokens map[tokenKey]Authorization
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[tokenKey]Authorization)}
+}
+
+func (store *MemoryTokenStore) Load(ctx context.Context, clientID int64, userID int64) (*Authorization, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	auth, ok := store.tokens[tokenKey{clientID, userID}]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+
+	return &auth, nil
+}
+
+func (store *MemoryTokenStore) Save(ctx context.Context, clientID int64, userID int64, auth Authorization) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.tokens[tokenKey{clientID, userID}] = auth
+	return nil
+}
+
+func (store *MemoryTokenStore) Delete(ctx context.Context, clientID int64, userID int64) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.tokens, tokenKey{clientID, userID})
+	return nil
+}
+
+/*
+FileTokenStore keeps the tokens of every user in a single file, as JSON encrypted with AES-256-GCM.
+The file is written atomically and it is only readable by its owner.
+It is meant to be used by a single process.
+*/
+type FileTokenStore struct {
+	path  string
+	aead  cipher.AEAD
+	mutex sync.Mutex
+}
+
+/*NewFileTokenStore returns a FileTokenStore which uses the file at path. The key has to be 32 bytes long.*/
+func NewFileTokenStore(path string, key []byte) (*FileTokenStore, error) {
+
+	if len(key) != 32 {
+		return nil, errors.New("the key of the token store must be 32 bytes long")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileTokenStore{path: path, aead: aead}, nil
+}
+
+func (store *FileTokenStore) Load(ctx context.Context, clientID int64, userID int64) (*Authorization, error) {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	tokens, err := store.read()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, ok := tokens[fileTokenKey(clientID, userID)]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+
+	return &auth, nil
+}
+
+func (store *FileTokenStore) Save(ctx context.Context, clientID int64, userID int64, auth Authorization) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	tokens, err := store.read()
+	if err != nil {
+		return err
+	}
+
+	tokens[fileTokenKey(clientID, userID)] = auth
+	return store.write(tokens)
+}
+
+func (store *FileTokenStore) Delete(ctx context.Context, clientID int64, userID int64) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	tokens, err := store.read()
+	if err != nil {
+		return err
+	}
+
+	delete(tokens, fileTokenKey(clientID, userID))
+	return store.write(tokens)
+}
+
+func fileTokenKey(clientID int64, userID int64) string {
+	return strconv.FormatInt(clientID, 10) + ":" + strconv.FormatInt(userID, 10)
+}
+
+func (store *FileTokenStore) read() (map[string]Authorization, error) {
+
+	tokens := make(map[string]Authorization)
+
+	data, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := store.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("the token store file is corrupted")
+	}
+
+	plain, err := store.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.New("the token store file could not be decrypted")
+	}
+
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (store *FileTokenStore) write(tokens map[string]Authorization) error {
+
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, store.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	data := store.aead.Seal(nonce, nonce, plain, nil)
+
+	tmp, err := ioutil.TempFile(filepath.Dir(store.path), "."+strings.TrimPrefix(filepath.Base(store.path), ".")+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), store.path)
+}
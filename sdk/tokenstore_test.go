@@ -0,0 +1,177 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+var testTokenStoreKey = []byte("0123456789abcdef0123456789abcdef")
+
+func Test_FileTokenStore_saves_loads_and_deletes_encrypted_tokens(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "tokens")
+	ctx := context.Background()
+
+	store, err := NewFileTokenStore(path, testTokenStoreKey)
+	if err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	auth := Authorization{AccessToken: "valid token", RefreshToken: "valid refresh token", UserID: 214509008}
+
+	if err := store.Save(ctx, CLIENT_ID, auth.UserID, auth); err != nil {
+		log.Printf("Error while saving the token: %s\n", err)
+		t.FailNow()
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if bytes.Contains(data, []byte("valid refresh token")) {
+		log.Printf("Error: the token was stored in plain text\n")
+		t.FailNow()
+	}
+
+	//A new store reading the same file has to find the token
+	store, _ = NewFileTokenStore(path, testTokenStoreKey)
+
+	loaded, err := store.Load(ctx, CLIENT_ID, auth.UserID)
+	if err != nil || *loaded != auth {
+		log.Printf("Error: the stored token was not loaded properly %v %v\n", loaded, err)
+		t.FailNow()
+	}
+
+	if err := store.Delete(ctx, CLIENT_ID, auth.UserID); err != nil {
+		log.Printf("Error while deleting the token: %s\n", err)
+		t.FailNow()
+	}
+
+	if _, err := store.Load(ctx, CLIENT_ID, auth.UserID); !errors.Is(err, ErrTokenNotFound) {
+		log.Printf("Error: ErrTokenNotFound was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+func Test_FileTokenStore_cannot_be_read_with_another_key(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "tokens")
+	ctx := context.Background()
+
+	store, _ := NewFileTokenStore(path, testTokenStoreKey)
+	store.Save(ctx, CLIENT_ID, 1, Authorization{AccessToken: "valid token"})
+
+	otherStore, _ := NewFileTokenStore(path, []byte("fedcba9876543210fedcba9876543210"))
+
+	if _, err := otherStore.Load(ctx, CLIENT_ID, 1); err == nil {
+		log.Printf("Error: the file should not have been decrypted\n")
+		t.FailNow()
+	}
+}
+
+func Test_client_is_built_from_the_token_saved_by_the_authorization(t *testing.T) {
+
+	store := NewMemoryTokenStore()
+
+	config := MeliConfig{
+		ClientID:    CLIENT_ID,
+		UserCode:    "STORED_CLIENT",
+		Secret:      CLIENT_SECRET,
+		CallBackURL: "http://www.example.com",
+		HTTPClient:  MockHttpClient{},
+		TokenStore:  store,
+	}
+
+	if _, err := MeliClient(config); err != nil {
+		log.Printf("Error: %s\n", err)
+		t.FailNow()
+	}
+
+	//After a restart, the code can not be used again, but the user id is known
+	config.UserCode = ""
+	config.UserID = 214509008
+
+	client, err := MeliClient(config)
+
+	if err != nil || !client.IsAuthorized() || client.Authorization().RefreshToken != "valid refresh token" {
+		log.Printf("Error: the client should have been built from the stored token %v\n", err)
+		t.FailNow()
+	}
+
+	config.UserID = 1
+
+	if _, err := MeliClient(config); !errors.Is(err, ErrTokenNotFound) {
+		log.Printf("Error: ErrTokenNotFound was expected, obtained %v\n", err)
+		t.FailNow()
+	}
+}
+
+/*failingTokenStore can not save any token.*/
+type failingTokenStore struct{}
+
+func (store failingTokenStore) Load(ctx context.Context, clientID int64, userID int64) (*Authorization, error) {
+	return nil, ErrTokenNotFound
+}
+
+func (store failingTokenStore) Save(ctx context.Context, clientID int64, userID int64, auth Authorization) error {
+	return errors.New("the store is not available")
+}
+
+func (store failingTokenStore) Delete(ctx context.Context, clientID int64, userID int64) error {
+	return nil
+}
+
+func Test_tokens_which_can_not_be_saved_are_kept_by_the_client(t *testing.T) {
+
+	config := MeliConfig{
+		ClientID:     CLIENT_ID,
+		UserCode:     USER_CODE,
+		Secret:       CLIENT_SECRET,
+		CallBackURL:  "http://www.example.com",
+		HTTPClient:   MockHttpClient{},
+		TokenStore:   failingTokenStore{},
+		DisableCache: true,
+	}
+
+	//The code can not be exchanged again
+	client, err := MeliClient(config)
+	if !errors.Is(err, ErrTokenNotSaved) || client == nil || client.Authorization().RefreshToken != "valid refresh token" {
+		log.Printf("Error: the authorized client should have been returned along with ErrTokenNotSaved, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	//The refresh token given was rotated
+	config.UserCode = ""
+	client, err = NewClientFromRefreshToken(config, "valid refresh token")
+	if !errors.Is(err, ErrTokenNotSaved) || client == nil || !client.IsAuthorized() {
+		log.Printf("Error: the refreshed client should have been returned along with ErrTokenNotSaved, obtained %v\n", err)
+		t.FailNow()
+	}
+
+	//The calls which refresh the token go on
+	client, _ = NewClientFromToken(config, Authorization{AccessToken: "expired token", RefreshToken: "valid refresh token"})
+	if resp, err := client.Get("/users/me"); err != nil || resp.StatusCode != http.StatusOK {
+		log.Printf("Error: the call should have used the refreshed token, obtained %v\n", err)
+		t.FailNow()
+	}
+}
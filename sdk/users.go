@@ -0,0 +1,187 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+/*User is a mercadolibre user. The private fields (i.e. Email) are only sent for the user who authorized the client.*/
+type User struct {
+	ID               int64             `json:"id"`
+	Nickname         string            `json:"nickname"`
+	FirstName        string            `json:"first_name"`
+	LastName         string            `json:"last_name"`
+	Email            string            `json:"email"`
+	CountryID        string            `json:"country_id"`
+	SiteID           string            `json:"site_id"`
+	UserType         string            `json:"user_type"`
+	Permalink        string            `json:"permalink"`
+	Points           int               `json:"points"`
+	RegistrationDate *time.Time        `json:"registration_date"`
+	Tags             []string          `json:"tags"`
+	SellerReputation *SellerReputation `json:"seller_reputation"`
+	Status           *UserStatus       `json:"status"`
+}
+
+/*SellerReputation is the reputation a user earned by selling.*/
+type SellerReputation struct {
+	LevelID           string `json:"level_id"`
+	PowerSellerStatus string `json:"power_seller_status"`
+	Transactions      struct {
+		Period    string `json:"period"`
+		Total     int    `json:"total"`
+		Completed int    `json:"completed"`
+		Canceled  int    `json:"canceled"`
+		Ratings   struct {
+			Positive float64 `json:"positive"`
+			Negative float64 `json:"negative"`
+			Neutral  float64 `json:"neutral"`
+		} `json:"ratings"`
+	} `json:"transactions"`
+}
+
+/*UserStatus tells whether the user is active and what the user is allowed to do.*/
+type UserStatus struct {
+	SiteStatus string `json:"site_status"`
+	List       struct {
+		Allow bool `json:"allow"`
+	} `json:"list"`
+	Buy struct {
+		Allow bool `json:"allow"`
+	} `json:"buy"`
+	Sell struct {
+		Allow bool `json:"allow"`
+	} `json:"sell"`
+	MercadoPagoAccountType string `json:"mercadopago_account_type"`
+}
+
+/*Address is one of the addresses of a user.*/
+type Address struct {
+	ID           int64    `json:"id"`
+	AddressLine  string   `json:"address_line"`
+	StreetName   string   `json:"street_name"`
+	StreetNumber string   `json:"street_number"`
+	ZipCode      string   `json:"zip_code"`
+	Comment      string   `json:"comment"`
+	Types        []string `json:"types"` // i.e. default_buying_address, shipping
+	City         struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"city"`
+	State struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"state"`
+	Country struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"country"`
+}
+
+/*UsersService gives typed access to the /users resources.*/
+type UsersService struct {
+	client *Client
+}
+
+/*Users returns the service to retrieve users.*/
+func (client *Client) Users() *UsersService {
+	return &UsersService{client: client}
+}
+
+/*Me retrieves the user who authorized the client.*/
+func (service *UsersService) Me(ctx context.Context) (*User, error) {
+
+	user := new(User)
+	if err := service.client.getJSON(ctx, "/users/me", user); err != nil {
+		return nil, err
+	}
+
+	service.client.cacheUserID(user.ID)
+
+	return user, nil
+}
+
+/*Get retrieves the user with the given id.*/
+func (service *UsersService) Get(ctx context.Context, id int64) (*User, error) {
+
+	user := new(User)
+	if err := service.client.getJSON(ctx, "/users/"+strconv.FormatInt(id, 10), user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+/*Addresses retrieves the addresses of the user with the given id, which has to be the user who authorized the client.*/
+func (service *UsersService) Addresses(ctx context.Context, id int64) ([]Address, error) {
+
+	var addresses []Address
+	if err := service.client.getJSON(ctx, "/users/"+strconv.FormatInt(id, 10)+"/addresses", &addresses); err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+/*
+UserID returns the id of the user who authorized the client. It is carried by the token, so the API is only
+asked for it (by calling /users/me once) when the token did not include it.
+*/
+func (client *Client) UserID(ctx context.Context) (int64, error) {
+
+	if userID := client.knownUserID(); userID != 0 {
+		return userID, nil
+	}
+
+	if client.application || !client.IsAuthorized() {
+		return 0, errors.New("the client is not authorized by any user")
+	}
+
+	user, err := client.Users().Me(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.ID, nil
+}
+
+/*knownUserID returns the id of the user carried by the token or cached by UserID, or 0 if it is not known yet.*/
+func (client *Client) knownUserID() int64 {
+
+	client.authMutex.RLock()
+	defer client.authMutex.RUnlock()
+
+	if client.auth.UserID != 0 {
+		return client.auth.UserID
+	}
+
+	return client.userID
+}
+
+func (client *Client) cacheUserID(userID int64) {
+
+	client.authMutex.Lock()
+	defer client.authMutex.Unlock()
+
+	if client.auth != anonymous && !client.application {
+		client.userID = userID
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright [2016] [mercadolibre.com]
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"testing"
+)
+
+const testUserPayload = `{"id":214509008,"nickname":"TEST_SELLER","site_id":"MLA","email":"seller@example.com",
+	"seller_reputation":{"level_id":"5_green","power_seller_status":"platinum","transactions":{"total":10,"completed":9,"canceled":1,"ratings":{"positive":0.9}}},
+	"status":{"site_status":"active","sell":{"allow":true}}}`
+
+func Test_Users_Me_Get_and_Addresses_return_typed_values(t *testing.T) {
+
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/me", "/users/214509008":
+			w.Write([]byte(testUserPayload))
+		case "/users/214509008/addresses":
+			w.Write([]byte(`[{"id":1,"street_name":"Corrientes","street_number":"1234","types":["default_selling_address"],"city":{"name":"Buenos Aires"}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer closeServer()
+
+	users := client.Users()
+	ctx := context.Background()
+
+	me, err := users.Me(ctx)
+	if err != nil || me.Nickname != "TEST_SELLER" || me.SellerReputation.PowerSellerStatus != "platinum" ||
+		me.SellerReputation.Transactions.Ratings.Positive != 0.9 || !me.Status.Sell.Allow {
+		log.Printf("Error: unexpected user %+v %v\n", me, err)
+		t.FailNow()
+	}
+
+	user, err := users.Get(ctx, 214509008)
+	if err != nil || user.ID != 214509008 {
+		log.Printf("Error: unexpected user %+v %v\n", user, err)
+		t.FailNow()
+	}
+
+	addresses, err := users.Addresses(ctx, 214509008)
+	if err != nil || len(addresses) != 1 || addresses[0].City.Name != "Buenos Aires" || addresses[0].Types[0] != "default_selling_address" {
+		log.Printf("Error: unexpected addresses %+v %v\n", addresses, err)
+		t.FailNow()
+	}
+}
+
+func Test_UserID_is_taken_from_the_token(t *testing.T) {
+
+	calls := 0
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+	defer closeServer()
+
+	userID, err := client.UserID(context.Background())
+	if err != nil || userID != 214509008 || calls != 0 {
+		log.Printf("Error: the user id should have been taken from the token, obtained %d %v after %d calls\n", userID, err, calls)
+		t.FailNow()
+	}
+}
+
+func Test_UserID_is_resolved_once_when_the_token_does_not_carry_it(t *testing.T) {
+
+	calls := 0
+	client, closeServer := newServiceTestClient(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(testUserPayload))
+	})
+	defer closeServer()
+
+	auth := client.Authorization()
+	auth.UserID = 0
+	client.setAuthorization(auth)
+
+	for i := 0; i < 3; i++ {
+		userID, err := client.UserID(context.Background())
+		if err != nil || userID != 214509008 {
+			log.Printf("Error: unexpected user id %d %v\n", userID, err)
+			t.FailNow()
+		}
+	}
+
+	if calls != 1 {
+		log.Printf("Error: /users/me should have been called once, it was called %d times\n", calls)
+		t.FailNow()
+	}
+
+	client.setAuthorization(anonymous)
+
+	if _, err := client.UserID(context.Background()); err == nil {
+		log.Printf("Error: the user id should have been forgotten with the authorization\n")
+		t.FailNow()
+	}
+}